@@ -0,0 +1,89 @@
+// Command configdoc prints reference docs for the config types of this
+// module: a Markdown table, a JSON Schema or a sample yaml file.
+//
+//	go run ./cmd/configdoc --type kube --format markdown
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/sangrita-tech/platform-go-pkg/pkg/config"
+	"github.com/sangrita-tech/platform-go-pkg/pkg/healthcheck"
+	httpclient "github.com/sangrita-tech/platform-go-pkg/pkg/http_client"
+	"github.com/sangrita-tech/platform-go-pkg/pkg/kube"
+	"github.com/sangrita-tech/platform-go-pkg/pkg/leaderelection"
+	"github.com/sangrita-tech/platform-go-pkg/pkg/logger"
+	"github.com/spf13/pflag"
+)
+
+type generator func(w io.Writer, format string, opts ...config.Option) error
+
+var types = map[string]generator{
+	"healthcheck":    generate[healthcheck.Config],
+	"httpclient":     generate[httpclient.Config],
+	"kube":           generate[kube.Config],
+	"leaderelection": generate[leaderelection.Config],
+	"logger":         generate[logger.Config],
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "configdoc ->", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, w io.Writer) error {
+	fs := pflag.NewFlagSet("configdoc", pflag.ContinueOnError)
+	typ := fs.String("type", "", "config type to document: "+strings.Join(typeNames(), ", "))
+	format := fs.String("format", "markdown", "output format: markdown, schema or yaml")
+	envPrefix := fs.String("env-prefix", "", "prefix prepended to every env var name")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	gen, ok := types[*typ]
+	if !ok {
+		return fmt.Errorf("unknown type %q, expected one of %s", *typ, strings.Join(typeNames(), ", "))
+	}
+
+	return gen(w, *format, config.WithEnvPrefix(*envPrefix))
+}
+
+func generate[T any](w io.Writer, format string, opts ...config.Option) error {
+	switch format {
+	case "markdown":
+		return config.Markdown[T](w, opts...)
+	case "schema":
+		out, err := config.JSONSchema[T](opts...)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(out))
+		return err
+	case "yaml":
+		out, err := config.SampleYAML[T](opts...)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(out)
+		return err
+	default:
+		return errors.New("unknown format " + format)
+	}
+}
+
+func typeNames() []string {
+	names := make([]string, 0, len(types))
+	for name := range types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -1,27 +1,322 @@
 package config
 
 import (
+	"context"
 	"errors"
-	"os"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
 
 	"github.com/ilyakaznacheev/cleanenv"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
 )
 
-func Load[T any](path string) (T, error) {
+// Load reads the file at path (if any) and then the environment into T.
+// Precedence, lowest first: env-default tags, the file, env vars.
+// A missing file is not an error; the config is then read from env only.
+func Load[T any](path string, opts ...Option) (T, error) {
+	sources := []Source{Defaults()}
+	if path != "" {
+		sources = append(sources, File(path))
+	}
+	sources = append(sources, Env())
+
+	return LoadFrom[T](sources, opts...)
+}
+
+// LoadFrom applies sources to T in order, later sources taking precedence.
+// Once all of them are applied it calls the cleanenv.Updater
+// implementations in the config, then checks env-required fields and
+// validate tag rules and calls the Validators; all problems are reported
+// together as a *ValidationError.
+func LoadFrom[T any](sources []Source, opts ...Option) (T, error) {
+	cfg, _, err := load[T](sources, opts)
+	return cfg, err
+}
+
+func load[T any](sources []Source, opts []Option) (T, *loader, error) {
 	var cfg T
 
-	if path == "" {
-		return cfg, cleanenv.ReadEnv(&cfg)
+	rv := reflect.ValueOf(&cfg)
+	if rv.Elem().Kind() != reflect.Struct {
+		return cfg, nil, fmt.Errorf("config -> %T is not a struct", cfg)
 	}
 
-	if err := cleanenv.ReadConfig(path, &cfg); err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			var envCfg T
-			return envCfg, cleanenv.ReadEnv(&envCfg)
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	l := newLoader(rv, o)
+	if slices.ContainsFunc(sources, isEnv) {
+		if err := l.checkEnvCollisions(); err != nil {
+			var zero T
+			return zero, nil, fmt.Errorf("config -> %w", err)
+		}
+	}
+
+	for _, src := range sources {
+		if err := src.apply(l); err != nil {
+			var zero T
+			return zero, nil, fmt.Errorf("config -> failed to load %s -> %w", src, err)
 		}
+	}
+
+	if err := update(l.root.Elem(), ""); err != nil {
+		var zero T
+		return zero, nil, fmt.Errorf("config -> %w", err)
+	}
+
+	if err := l.validate(); err != nil {
 		var zero T
-		return zero, err
+		return zero, nil, fmt.Errorf("config -> failed to validate config -> %w", err)
+	}
+
+	return cfg, l, nil
+}
+
+// update calls the cleanenv.Updater implementations of the nested structs
+// of v and then of v itself, so that an Updater sees its fields updated.
+func update(v reflect.Value, name string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() || !isNested(sf.Type) {
+			continue
+		}
+		if err := update(v.Field(i), joinPath(name, sf.Name)); err != nil {
+			return err
+		}
+	}
+
+	u, ok := v.Addr().Interface().(cleanenv.Updater)
+	if !ok {
+		return nil
+	}
+	if err := u.Update(); err != nil {
+		if name == "" {
+			return fmt.Errorf("failed to update config -> %w", err)
+		}
+		return fmt.Errorf("failed to update %s -> %w", name, err)
+	}
+	return nil
+}
+
+// Option changes how LoadFrom applies its sources.
+type Option func(*options)
+
+type options struct {
+	ctx         context.Context
+	strictFiles bool
+	strictKeys  bool
+	envPrefix   string
+	logger      *zap.Logger
+	version     string
+}
+
+// WithContext sets the context sources that call an API, like ConfigMap,
+// read with. It defaults to context.Background().
+func WithContext(ctx context.Context) Option {
+	return func(o *options) {
+		o.ctx = ctx
+	}
+}
+
+// WithStrictFiles makes a missing File or Dir source an error instead of
+// being skipped.
+func WithStrictFiles() Option {
+	return func(o *options) {
+		o.strictFiles = true
+	}
+}
+
+// WithStrictKeys makes keys of yaml, json and toml documents that match no
+// field an error, so a typo does not silently leave the default in place.
+func WithStrictKeys() Option {
+	return func(o *options) {
+		o.strictKeys = true
+	}
+}
+
+// WithLogger sets the logger deprecation warnings are written to. It
+// defaults to logger.L() at the time of the warning.
+func WithLogger(l *zap.Logger) Option {
+	return func(o *options) {
+		o.logger = l
+	}
+}
+
+// WithVersion sets the version deprecated-until tags are compared with. It
+// defaults to version.Version.
+func WithVersion(v string) Option {
+	return func(o *options) {
+		o.version = v
+	}
+}
+
+// WithEnvPrefix prepends prefix to every env var name, including the ones
+// already namespaced with env-prefix tags: with WithEnvPrefix("APP_") a
+// field tagged env:"QPS" inside a struct tagged env-prefix:"KUBE_" is read
+// from APP_KUBE_QPS.
+func WithEnvPrefix(prefix string) Option {
+	return func(o *options) {
+		o.envPrefix = prefix
+	}
+}
+
+type loader struct {
+	opts           options
+	root           reflect.Value
+	fields         []*field
+	byKey          map[string]*field
+	prefixes       map[string]bool
+	deprecatedKeys map[string]*field
+	origins        map[*field]Origin
+}
+
+func newLoader(root reflect.Value, opts options) *loader {
+	l := &loader{
+		opts:           opts,
+		root:           root,
+		fields:         collectFields(root, opts.envPrefix),
+		byKey:          make(map[string]*field),
+		prefixes:       make(map[string]bool),
+		deprecatedKeys: make(map[string]*field),
+		origins:        make(map[*field]Origin),
+	}
+	for _, f := range l.fields {
+		if f.key == "" {
+			continue
+		}
+		l.byKey[f.key] = f
+		parts := strings.Split(f.key, ".")
+		for i := 1; i < len(parts); i++ {
+			l.prefixes[strings.Join(parts[:i], ".")] = true
+		}
+		for _, old := range f.deprecatedKeys {
+			l.deprecatedKeys[old] = f
+		}
+	}
+	return l
+}
+
+func (l *loader) set(f *field, raw string, origin Origin) error {
+	if err := parseValue(f.value, raw, f.sep, f.layout); err != nil {
+		return fmt.Errorf("field %s -> %w", f.name, err)
+	}
+	l.origins[f] = origin
+	return nil
+}
+
+func (l *loader) applyRaw(source, path string, raw []byte) error {
+	format, err := formatOf(path)
+	if err != nil {
+		return err
+	}
+
+	doc, err := decodeDocument(path, format, raw)
+	if err != nil {
+		return err
+	}
+	return l.applyDocument(source, doc)
+}
+
+func (l *loader) applyDocument(source string, doc *document) error {
+	for _, node := range doc.nodes {
+		if node.Kind == 0 {
+			continue
+		}
+		if err := l.renameDeprecated(doc.name, source, node, ""); err != nil {
+			return err
+		}
+		if l.opts.strictKeys {
+			if err := errors.Join(l.checkKeys(doc.name, node, "")...); err != nil {
+				return err
+			}
+		}
+		if err := node.Decode(l.root.Interface()); err != nil {
+			return l.locateDecodeError(doc.name, node, err)
+		}
+		l.markNode(node, "", source)
+	}
+
+	if doc.env != nil {
+		return l.applyEnv(source, func(name string) (string, bool) {
+			v, ok := doc.env[name]
+			return v, ok
+		})
+	}
+	return nil
+}
+
+func (l *loader) applyEnv(source string, lookup func(string) (string, bool)) error {
+	for _, f := range l.fields {
+		env, raw, ok := lookupEnv(f, lookup)
+		if !ok {
+			var err error
+			env, raw, ok, err = l.lookupDeprecatedEnv(f, source, lookup)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+		}
+		if err := l.set(f, raw, Origin{Source: source, Key: env}); err != nil {
+			return fmt.Errorf("env %s -> %w", env, err)
+		}
 	}
+	return nil
+}
 
-	return cfg, cleanenv.ReadEnv(&cfg)
+// markNode records source as the origin of every field whose key is set
+// in node.
+func (l *loader) markNode(node *yaml.Node, prefix, source string) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, n := range node.Content {
+			l.markNode(n, prefix, source)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := joinPath(prefix, node.Content[i].Value)
+			if f, ok := l.byKey[key]; ok {
+				l.origins[f] = Origin{Source: source, Key: key}
+				continue
+			}
+			l.markNode(node.Content[i+1], key, source)
+		}
+	}
+}
+
+// checkEnvCollisions reports env vars that more than one field reads from,
+// which usually means two composed configs share a generic name such as
+// TIMEOUT and need an env-prefix. It only runs when the config is loaded
+// from Env.
+func (l *loader) checkEnvCollisions() error {
+	users := make(map[string][]string)
+	var envs []string
+
+	for _, f := range l.fields {
+		for _, env := range f.envs {
+			if _, seen := users[env]; !seen {
+				envs = append(envs, env)
+			}
+			users[env] = append(users[env], f.name)
+		}
+	}
+
+	var collisions []string
+	for _, env := range envs {
+		if len(users[env]) > 1 {
+			collisions = append(collisions, fmt.Sprintf("%s used by %s", env, strings.Join(users[env], ", ")))
+		}
+	}
+
+	if len(collisions) == 0 {
+		return nil
+	}
+	return fmt.Errorf("env var collision -> %s", strings.Join(collisions, "; "))
 }
@@ -1,6 +1,7 @@
 package config_test
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -103,3 +104,52 @@ func Test_Load_ParseError_ReturnsErrorAndZero(t *testing.T) {
 	require.Error(t, err)
 	require.Equal(t, testCfg{}, cfg)
 }
+
+type dsnCfg struct {
+	Host string `yaml:"host" env:"DB_HOST" env-default:"localhost"`
+	DSN  string `yaml:"-"`
+}
+
+func (c *dsnCfg) Update() error {
+	c.DSN = "postgres://" + c.Host
+	return nil
+}
+
+type updatedCfg struct {
+	DB      dsnCfg `yaml:"db"`
+	Summary string `yaml:"-"`
+}
+
+func (c *updatedCfg) Update() error {
+	if c.DB.DSN == "" {
+		return errors.New("db not updated yet")
+	}
+	c.Summary = "db at " + c.DB.DSN
+	return nil
+}
+
+type failingUpdateCfg struct {
+	DB struct {
+		brokenUpdater
+	} `yaml:"db"`
+}
+
+type brokenUpdater struct{}
+
+func (*brokenUpdater) Update() error { return errors.New("no dsn") }
+
+func Test_LoadFrom_Updaters_CalledAfterSourcesNestedFirst(t *testing.T) {
+	t.Setenv("DB_HOST", "db.internal")
+
+	cfg, err := config.LoadFrom[updatedCfg]([]config.Source{config.Defaults(), config.Env()})
+
+	require.NoError(t, err)
+	require.Equal(t, "postgres://db.internal", cfg.DB.DSN)
+	require.Equal(t, "db at postgres://db.internal", cfg.Summary)
+}
+
+func Test_LoadFrom_UpdaterFails_ReturnsError(t *testing.T) {
+	_, err := config.LoadFrom[failingUpdateCfg]([]config.Source{config.Defaults()})
+
+	require.ErrorContains(t, err, "config -> failed to update DB -> no dsn")
+}
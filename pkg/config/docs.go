@@ -0,0 +1,351 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const durationPattern = `^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+
+// Markdown writes a reference table of every field of T: env vars, yaml key,
+// type, default, whether it is required and its env-description.
+func Markdown[T any](w io.Writer, opts ...Option) error {
+	var b strings.Builder
+
+	b.WriteString("| Env | YAML key | Type | Default | Required | Description |\n")
+	b.WriteString("|-----|----------|------|---------|----------|-------------|\n")
+
+	for _, f := range docFields[T](opts) {
+		envs := make([]string, len(f.envs))
+		for i, env := range f.envs {
+			envs[i] = "`" + env + "`"
+		}
+
+		key := ""
+		if f.key != "" {
+			key = "`" + f.key + "`"
+		}
+
+		def := ""
+		if f.def != nil && *f.def != "" {
+			def = "`" + *f.def + "`"
+		}
+
+		required := ""
+		if f.required || hasRule(f.rules, "required") {
+			required = "yes"
+		}
+
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s |\n",
+			strings.Join(envs, ", "), key, typeName(f.value.Type()), def, required, escapeCell(f.desc))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// JSONSchema returns a JSON Schema for yaml, json and toml files of T that
+// editors can use for completion and validation. Deprecated keys, which
+// LoadFrom still accepts, are listed and marked deprecated.
+func JSONSchema[T any](opts ...Option) ([]byte, error) {
+	root := &schema{
+		Schema:               "https://json-schema.org/draft/2020-12/schema",
+		Title:                reflect.TypeOf((*T)(nil)).Elem().String(),
+		Type:                 "object",
+		Properties:           map[string]*schema{},
+		AdditionalProperties: false,
+	}
+
+	for _, f := range docFields[T](opts) {
+		if f.key == "" {
+			continue
+		}
+
+		s := typeSchema(f.value.Type())
+		s.Description = f.desc
+		if f.def != nil {
+			s.Default = defaultValue(f)
+		}
+		applyRules(s, f)
+		root.set(f.key, s)
+
+		// Deprecated keys are still accepted, so files using them must
+		// validate.
+		for _, old := range f.deprecatedKeys {
+			d := typeSchema(f.value.Type())
+			d.Deprecated = true
+			d.Description = "Deprecated, use " + f.key + "."
+			if f.deprecatedUntil != "" {
+				d.Description = "Deprecated, use " + f.key + "; removed in " + f.deprecatedUntil + "."
+			}
+			root.set(old, d)
+		}
+	}
+
+	return json.MarshalIndent(root, "", "  ")
+}
+
+// set adds s as the property at the dotted key, adding the objects on the
+// way.
+func (root *schema) set(key string, s *schema) {
+	parent := root
+	parts := strings.Split(key, ".")
+	for _, part := range parts[:len(parts)-1] {
+		child, ok := parent.Properties[part]
+		if !ok {
+			child = &schema{Type: "object", Properties: map[string]*schema{}, AdditionalProperties: false}
+			parent.Properties[part] = child
+		}
+		parent = child
+	}
+	parent.Properties[parts[len(parts)-1]] = s
+}
+
+// SampleYAML returns a yaml file of T filled with the env-default values and
+// commented with the env-descriptions and env vars of each field.
+func SampleYAML[T any](opts ...Option) ([]byte, error) {
+	root := &yaml.Node{Kind: yaml.MappingNode}
+
+	for _, f := range docFields[T](opts) {
+		if f.key == "" {
+			continue
+		}
+
+		parent := root
+		parts := strings.Split(f.key, ".")
+		for _, part := range parts[:len(parts)-1] {
+			parent = mappingChild(parent, part)
+		}
+
+		var value yaml.Node
+		if err := value.Encode(sampleValue(f)); err != nil {
+			return nil, fmt.Errorf("config -> failed to encode sample for %s -> %w", f.name, err)
+		}
+
+		key := &yaml.Node{Kind: yaml.ScalarNode, Value: parts[len(parts)-1], HeadComment: fieldComment(f)}
+		parent.Content = append(parent.Content, key, &value)
+	}
+
+	return yaml.Marshal(&yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}})
+}
+
+type schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Default              any                `json:"default,omitempty"`
+	Deprecated           bool               `json:"deprecated,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Items                *schema            `json:"items,omitempty"`
+	Properties           map[string]*schema `json:"properties,omitempty"`
+	AdditionalProperties any                `json:"additionalProperties,omitempty"`
+}
+
+func docFields[T any](opts []Option) []*field {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var cfg T
+	return collectFields(reflect.ValueOf(&cfg), o.envPrefix)
+}
+
+func typeSchema(t reflect.Type) *schema {
+	switch t {
+	case durationType:
+		return &schema{Type: "string", Pattern: durationPattern}
+	case secretType:
+		return &schema{Type: "string"}
+	case reflect.TypeOf(time.Time{}):
+		return &schema{Type: "string", Format: "date-time"}
+	case reflect.TypeOf(url.URL{}):
+		return &schema{Type: "string", Format: "uri"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &schema{Type: "string"}
+	case reflect.Bool:
+		return &schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &schema{Type: "number"}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &schema{Type: "string"}
+		}
+		return &schema{Type: "array", Items: typeSchema(t.Elem())}
+	case reflect.Map:
+		return &schema{Type: "object", AdditionalProperties: typeSchema(t.Elem())}
+	case reflect.Pointer:
+		return typeSchema(t.Elem())
+	default:
+		return &schema{}
+	}
+}
+
+func applyRules(s *schema, f *field) {
+	for _, rule := range strings.Split(f.rules, ",") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+
+		switch name {
+		case "min", "max":
+			if f.value.Type() == durationType {
+				continue
+			}
+			n, err := strconv.ParseFloat(arg, 64)
+			if err != nil {
+				continue
+			}
+			setBound(s, name == "min", n)
+		case "oneof":
+			for _, v := range strings.Fields(arg) {
+				s.Enum = append(s.Enum, enumValue(s.Type, v))
+			}
+		case "url":
+			s.Format = "uri"
+		}
+	}
+}
+
+func setBound(s *schema, isMin bool, n float64) {
+	i := int(n)
+
+	switch s.Type {
+	case "integer", "number":
+		if isMin {
+			s.Minimum = &n
+		} else {
+			s.Maximum = &n
+		}
+	case "string":
+		if isMin {
+			s.MinLength = &i
+		} else {
+			s.MaxLength = &i
+		}
+	case "array":
+		if isMin {
+			s.MinItems = &i
+		} else {
+			s.MaxItems = &i
+		}
+	}
+}
+
+func enumValue(typ, v string) any {
+	switch typ {
+	case "integer", "number":
+		if n, err := strconv.ParseFloat(v, 64); err == nil {
+			return n
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+	return v
+}
+
+// defaultValue converts the env-default of f to the value it decodes to, so
+// that numbers and lists show up typed in the schema.
+func defaultValue(f *field) any {
+	v := reflect.New(f.value.Type()).Elem()
+	if err := parseValue(v, *f.def, f.sep, f.layout); err != nil {
+		return *f.def
+	}
+	if v.Type() == durationType {
+		return *f.def
+	}
+	return displayValue(v)
+}
+
+func sampleValue(f *field) any {
+	if f.def != nil {
+		return defaultValue(f)
+	}
+	return displayValue(reflect.New(f.value.Type()).Elem())
+}
+
+func mappingChild(n *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+
+	child := &yaml.Node{Kind: yaml.MappingNode}
+	n.Content = append(n.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, child)
+	return child
+}
+
+func fieldComment(f *field) string {
+	var parts []string
+	if f.desc != "" {
+		parts = append(parts, f.desc)
+	}
+	if len(f.envs) > 0 {
+		parts = append(parts, "env: "+strings.Join(f.envs, ", "))
+	}
+	return strings.Join(parts, "\n")
+}
+
+func typeName(t reflect.Type) string {
+	switch t {
+	case durationType:
+		return "duration"
+	case secretType:
+		return "secret"
+	case reflect.TypeOf(time.Time{}):
+		return "time"
+	case reflect.TypeOf(url.URL{}):
+		return "url"
+	}
+
+	switch t.Kind() {
+	case reflect.Slice:
+		return "[]" + typeName(t.Elem())
+	case reflect.Map:
+		return "map[" + typeName(t.Key()) + "]" + typeName(t.Elem())
+	case reflect.Pointer:
+		return typeName(t.Elem())
+	}
+
+	if t.PkgPath() == "" {
+		return t.Kind().String()
+	}
+	return t.String()
+}
+
+func hasRule(rules, name string) bool {
+	for _, rule := range strings.Split(rules, ",") {
+		if strings.TrimSpace(rule) == name {
+			return true
+		}
+	}
+	return false
+}
+
+func escapeCell(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "|", `\|`), "\n", " ")
+}
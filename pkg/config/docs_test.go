@@ -0,0 +1,104 @@
+package config_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/sangrita-tech/platform-go-pkg/pkg/config"
+	"github.com/sangrita-tech/platform-go-pkg/pkg/kube"
+	"github.com/stretchr/testify/require"
+)
+
+type docsCfg struct {
+	Name    string            `yaml:"name" env:"DOCS_NAME" env-default:"app" env-description:"service name | shown in logs"`
+	Mode    string            `yaml:"mode" env:"DOCS_MODE" env-default:"safe" validate:"oneof=fast safe"`
+	Workers int               `yaml:"workers" env:"DOCS_WORKERS" env-default:"2" validate:"min=1,max=8"`
+	Tags    []string          `yaml:"tags" env:"DOCS_TAGS" env-default:"a,b"`
+	Labels  map[string]string `yaml:"labels" env:"DOCS_LABELS"`
+	Token   config.Secret     `yaml:"token" env:"DOCS_TOKEN" env-required:"true"`
+	Kube    kube.Config       `yaml:"kube" env-prefix:"KUBE_"`
+}
+
+func Test_Markdown_ListsEveryField(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := config.Markdown[docsCfg](&buf, config.WithEnvPrefix("APP_"))
+
+	require.NoError(t, err)
+	out := buf.String()
+	require.Contains(t, out, "| `APP_DOCS_NAME` | `name` | string | `app` |  | service name \\| shown in logs |\n")
+	require.Contains(t, out, "| `APP_DOCS_TOKEN` | `token` | secret |  | yes |  |\n")
+	require.Contains(t, out, "| `APP_KUBE_TIMEOUT` | `kube.timeout` | duration | `30s` |  |  |\n")
+	require.Contains(t, out, "| `APP_DOCS_LABELS` | `labels` | map[string]string |  |  |  |\n")
+}
+
+func Test_JSONSchema_DescribesTypesDefaultsAndRules(t *testing.T) {
+	out, err := config.JSONSchema[docsCfg]()
+	require.NoError(t, err)
+
+	var s map[string]any
+	require.NoError(t, json.Unmarshal(out, &s))
+	require.Equal(t, "object", s["type"])
+	require.Equal(t, false, s["additionalProperties"])
+
+	props := s["properties"].(map[string]any)
+	require.Equal(t, []any{"fast", "safe"}, props["mode"].(map[string]any)["enum"])
+
+	workers := props["workers"].(map[string]any)
+	require.Equal(t, "integer", workers["type"])
+	require.Equal(t, 2.0, workers["default"])
+	require.Equal(t, 1.0, workers["minimum"])
+	require.Equal(t, 8.0, workers["maximum"])
+
+	require.Equal(t, []any{"a", "b"}, props["tags"].(map[string]any)["default"])
+
+	kubeProps := props["kube"].(map[string]any)["properties"].(map[string]any)
+	timeout := kubeProps["timeout"].(map[string]any)
+	require.Equal(t, "string", timeout["type"])
+	require.Equal(t, "30s", timeout["default"])
+}
+
+func Test_JSONSchema_DeprecatedKeys_ListedAsDeprecated(t *testing.T) {
+	out, err := config.JSONSchema[keysCfg]()
+	require.NoError(t, err)
+
+	var s map[string]any
+	require.NoError(t, json.Unmarshal(out, &s))
+
+	election := s["properties"].(map[string]any)["election"].(map[string]any)
+	require.Equal(t, false, election["additionalProperties"])
+	props := election["properties"].(map[string]any)
+
+	lease := props["lease"].(map[string]any)
+	require.Equal(t, true, lease["deprecated"])
+	require.Equal(t, "string", lease["type"])
+	require.Equal(t, "Deprecated, use election.leaseDuration; removed in v2.0.0.", lease["description"])
+	require.Equal(t, true, props["renew"].(map[string]any)["deprecated"])
+	require.NotContains(t, props["leaseDuration"], "deprecated")
+}
+
+func Test_SampleYAML_LoadsBackToDefaults(t *testing.T) {
+	for _, env := range []string{"DOCS_NAME", "DOCS_MODE", "DOCS_WORKERS", "DOCS_TAGS", "DOCS_LABELS", "KUBE_TIMEOUT"} {
+		os.Unsetenv(env)
+	}
+	t.Setenv("DOCS_TOKEN", "x")
+
+	out, err := config.SampleYAML[docsCfg]()
+	require.NoError(t, err)
+	require.Contains(t, string(out), "# service name | shown in logs\n# env: DOCS_NAME\nname: app\n")
+
+	path := writeTempFile(t, t.TempDir(), "sample.yaml", string(out))
+	fromSample, err := config.LoadFrom[docsCfg]([]config.Source{config.File(path), config.Env()})
+	require.NoError(t, err)
+	fromDefaults, err := config.LoadFrom[docsCfg]([]config.Source{config.Defaults(), config.Env()})
+	require.NoError(t, err)
+
+	require.Equal(t, fromDefaults.Name, fromSample.Name)
+	require.Equal(t, fromDefaults.Workers, fromSample.Workers)
+	require.Equal(t, fromDefaults.Tags, fromSample.Tags)
+	require.Equal(t, 30*time.Second, fromSample.Kube.Timeout)
+	require.Equal(t, fromDefaults.Kube, fromSample.Kube)
+}
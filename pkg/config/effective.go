@@ -0,0 +1,141 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+const PrintConfigFlag = "print-config"
+
+// Origin tells which source set a field and under which env var, yaml key
+// or flag. Source is empty for fields no source has set.
+type Origin struct {
+	Source string `json:"source,omitempty"`
+	Key    string `json:"key,omitempty"`
+}
+
+func (o Origin) String() string {
+	switch {
+	case o.Source == "":
+		return "unset"
+	case o.Key == "":
+		return o.Source
+	default:
+		return o.Source + " " + o.Key
+	}
+}
+
+// Node is one level of the effective config tree. Leaves carry the value of
+// a field, with secrets redacted, and its origin.
+type Node struct {
+	Key      string  `json:"key,omitempty"`
+	Field    string  `json:"field,omitempty"`
+	Value    any     `json:"value,omitempty"`
+	Origin   *Origin `json:"origin,omitempty"`
+	Children []*Node `json:"children,omitempty"`
+}
+
+// LoadEffective loads T like LoadFrom and also returns the effective config
+// as a tree annotated with the origin of every field.
+func LoadEffective[T any](sources []Source, opts ...Option) (T, *Node, error) {
+	cfg, l, err := load[T](sources, opts)
+	if err != nil {
+		return cfg, nil, err
+	}
+	return cfg, l.tree(), nil
+}
+
+// Print writes one "key = value  # origin" line per field.
+func (n *Node) Print(w io.Writer) error {
+	return n.print(w, "")
+}
+
+func (n *Node) print(w io.Writer, prefix string) error {
+	path := joinPath(prefix, n.Key)
+
+	if n.Origin != nil {
+		_, err := fmt.Fprintf(w, "%s = %v  # %s\n", path, n.Value, n.Origin)
+		return err
+	}
+
+	for _, c := range n.Children {
+		if err := c.print(w, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Handler serves n as JSON, for mounting on a debug route such as the
+// healthcheck server.
+func Handler(n *Node) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(n)
+	})
+}
+
+// DefinePrintFlag registers --print-config on fs.
+func DefinePrintFlag(fs *pflag.FlagSet) *bool {
+	return fs.Bool(PrintConfigFlag, false, "print the effective configuration with the source of every value and exit")
+}
+
+func (l *loader) tree() *Node {
+	root := &Node{}
+
+	for _, f := range l.fields {
+		path := f.key
+		if path == "" {
+			path = f.name
+		}
+
+		parent := root
+		parts := strings.Split(path, ".")
+		for _, part := range parts[:len(parts)-1] {
+			parent = parent.child(part)
+		}
+
+		origin := l.origins[f]
+		parent.Children = append(parent.Children, &Node{
+			Key:    parts[len(parts)-1],
+			Field:  f.name,
+			Value:  displayValue(f.value),
+			Origin: &origin,
+		})
+	}
+
+	return root
+}
+
+func (n *Node) child(key string) *Node {
+	for _, c := range n.Children {
+		if c.Key == key && c.Origin == nil {
+			return c
+		}
+	}
+
+	c := &Node{Key: key}
+	n.Children = append(n.Children, c)
+	return c
+}
+
+func displayValue(v reflect.Value) any {
+	if v.Kind() == reflect.Pointer && v.IsNil() {
+		return nil
+	}
+	if v.Type() == secretType {
+		return v.Interface().(Secret).String()
+	}
+	if s, ok := v.Interface().(fmt.Stringer); ok && v.Kind() != reflect.Struct {
+		return s.String()
+	}
+	return v.Interface()
+}
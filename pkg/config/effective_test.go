@@ -0,0 +1,124 @@
+package config_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/sangrita-tech/platform-go-pkg/pkg/config"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/require"
+)
+
+type effectiveCfg struct {
+	Name     string        `yaml:"name" env:"EFF_NAME" env-default:"app"`
+	Port     int           `yaml:"port" env:"EFF_PORT" env-default:"80"`
+	Timeout  time.Duration `yaml:"timeout" env:"EFF_TIMEOUT"`
+	Token    config.Secret `yaml:"token" env:"EFF_TOKEN"`
+	Unset    string        `yaml:"unset"`
+	Database struct {
+		Host string `yaml:"host" env:"EFF_DB_HOST" env-default:"localhost"`
+	} `yaml:"database"`
+}
+
+func loadEffective(t *testing.T) *config.Node {
+	t.Helper()
+
+	for _, env := range []string{"EFF_NAME", "EFF_PORT", "EFF_TIMEOUT", "EFF_TOKEN", "EFF_DB_HOST"} {
+		os.Unsetenv(env)
+	}
+	t.Setenv("EFF_PORT", "9090")
+	t.Setenv("EFF_TOKEN", "s3cr3t")
+	path := writeTempFile(t, t.TempDir(), "cfg.yaml", "timeout: 5s\ndatabase:\n  host: db.internal\n")
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	config.DefineFlags[effectiveCfg](fs)
+	require.NoError(t, fs.Parse([]string{"--name=from-flag"}))
+
+	_, tree, err := config.LoadEffective[effectiveCfg]([]config.Source{
+		config.Defaults(),
+		config.File(path),
+		config.Env(),
+		config.Flags(fs),
+	})
+	require.NoError(t, err)
+
+	return tree
+}
+
+func findNode(t *testing.T, n *config.Node, keys ...string) *config.Node {
+	t.Helper()
+
+	for _, key := range keys {
+		var next *config.Node
+		for _, c := range n.Children {
+			if c.Key == key {
+				next = c
+			}
+		}
+		require.NotNil(t, next, "no node %q", key)
+		n = next
+	}
+	return n
+}
+
+func Test_LoadEffective_AnnotatesEveryFieldWithOrigin(t *testing.T) {
+	tree := loadEffective(t)
+
+	name := findNode(t, tree, "name")
+	require.Equal(t, "from-flag", name.Value)
+	require.Equal(t, config.Origin{Source: "flags", Key: "--name"}, *name.Origin)
+
+	port := findNode(t, tree, "port")
+	require.Equal(t, 9090, port.Value)
+	require.Equal(t, config.Origin{Source: "env", Key: "EFF_PORT"}, *port.Origin)
+
+	timeout := findNode(t, tree, "timeout")
+	require.Equal(t, "5s", timeout.Value)
+	require.Equal(t, "timeout", timeout.Origin.Key)
+	require.Contains(t, timeout.Origin.Source, "cfg.yaml")
+
+	host := findNode(t, tree, "database", "host")
+	require.Equal(t, "db.internal", host.Value)
+	require.Equal(t, "Database.Host", host.Field)
+	require.Equal(t, "database.host", host.Origin.Key)
+
+	require.Equal(t, config.Origin{}, *findNode(t, tree, "unset").Origin)
+}
+
+func Test_LoadEffective_DefaultOrigin(t *testing.T) {
+	for _, env := range []string{"EFF_NAME", "EFF_PORT", "EFF_TIMEOUT", "EFF_TOKEN", "EFF_DB_HOST"} {
+		os.Unsetenv(env)
+	}
+
+	_, tree, err := config.LoadEffective[effectiveCfg]([]config.Source{config.Defaults(), config.Env()})
+	require.NoError(t, err)
+
+	port := findNode(t, tree, "port")
+	require.Equal(t, 80, port.Value)
+	require.Equal(t, "defaults", port.Origin.String())
+}
+
+func Test_Node_PrintAndHandler_RedactSecrets(t *testing.T) {
+	tree := loadEffective(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, tree.Print(&buf))
+	out := buf.String()
+	require.Contains(t, out, "database.host = db.internal  # file ")
+	require.Contains(t, out, "port = 9090  # env EFF_PORT\n")
+	require.Contains(t, out, "token = [REDACTED]  # env EFF_TOKEN\n")
+	require.NotContains(t, out, "s3cr3t")
+
+	rec := httptest.NewRecorder()
+	config.Handler(tree).ServeHTTP(rec, httptest.NewRequest("GET", "/debug/config", nil))
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	require.NotContains(t, rec.Body.String(), "s3cr3t")
+
+	var decoded config.Node
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &decoded))
+	require.Equal(t, "[REDACTED]", findNode(t, &decoded, "token").Value)
+}
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"encoding"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/ilyakaznacheev/cleanenv"
+)
+
+// field is a single settable leaf of a config struct together with the
+// metadata taken from its tags.
+type field struct {
+	name     string
+	key      string
+	envs     []string
+	def      *string
+	layout   *string
+	sep      string
+	desc     string
+	required bool
+	rules    string
+	value    reflect.Value
+	parent   reflect.Value
+
+	deprecatedKeys  []string
+	deprecatedEnvs  []string
+	deprecatedUntil string
+}
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	setterType          = reflect.TypeOf((*cleanenv.Setter)(nil)).Elem()
+
+	leafStructs = map[reflect.Type]bool{
+		reflect.TypeOf(time.Time{}): true,
+		reflect.TypeOf(url.URL{}):   true,
+	}
+)
+
+// collectFields flattens the struct pointed to by root into its leaf fields.
+// envPrefix is prepended to every env name, before any env-prefix tags.
+func collectFields(root reflect.Value, envPrefix string) []*field {
+	var out []*field
+	walkStruct(root.Elem(), "", "", envPrefix, &out)
+	return out
+}
+
+func walkStruct(v reflect.Value, name, key, envPrefix string, out *[]*field) {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		fv := v.Field(i)
+		fName := joinPath(name, sf.Name)
+		fKey, inline := yamlKey(sf)
+		if inline {
+			fKey = key
+		} else if fKey != "" {
+			fKey = joinPath(key, fKey)
+		}
+
+		if isNested(sf.Type) {
+			walkStruct(fv, fName, fKey, envPrefix+sf.Tag.Get(cleanenv.TagEnvPrefix), out)
+			continue
+		}
+
+		f := &field{
+			name:   fName,
+			key:    fKey,
+			sep:    cleanenv.DefaultSeparator,
+			desc:   sf.Tag.Get(cleanenv.TagEnvDescription),
+			rules:  sf.Tag.Get(tagValidate),
+			value:  fv,
+			parent: v,
+		}
+
+		if envs, ok := sf.Tag.Lookup(cleanenv.TagEnv); ok && envs != "" {
+			for _, env := range strings.Split(envs, cleanenv.DefaultSeparator) {
+				f.envs = append(f.envs, envPrefix+env)
+			}
+		}
+		if def, ok := sf.Tag.Lookup(cleanenv.TagEnvDefault); ok {
+			f.def = &def
+		}
+		if layout, ok := sf.Tag.Lookup(cleanenv.TagEnvLayout); ok {
+			f.layout = &layout
+		}
+		if sep, ok := sf.Tag.Lookup(cleanenv.TagEnvSeparator); ok {
+			f.sep = sep
+		}
+		_, f.required = sf.Tag.Lookup(cleanenv.TagEnvRequired)
+
+		if old := sf.Tag.Get(tagDeprecatedKey); old != "" && !inline {
+			for _, k := range strings.Split(old, ",") {
+				f.deprecatedKeys = append(f.deprecatedKeys, joinPath(key, k))
+			}
+		}
+		if old := sf.Tag.Get(tagDeprecatedEnv); old != "" {
+			for _, env := range strings.Split(old, ",") {
+				f.deprecatedEnvs = append(f.deprecatedEnvs, envPrefix+env)
+			}
+		}
+		f.deprecatedUntil = sf.Tag.Get(tagDeprecatedUntil)
+
+		*out = append(*out, f)
+	}
+}
+
+// isNested reports whether a field of type t is walked into rather than
+// treated as a single value.
+func isNested(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || leafStructs[t] {
+		return false
+	}
+
+	pt := reflect.PointerTo(t)
+	return !pt.Implements(textUnmarshalerType) && !pt.Implements(setterType)
+}
+
+// yamlKey returns the key yaml.v3 uses for sf and whether sf is inlined.
+func yamlKey(sf reflect.StructField) (string, bool) {
+	tag := sf.Tag.Get("yaml")
+	if tag == "-" {
+		return "", false
+	}
+
+	name, opts, _ := strings.Cut(tag, ",")
+	for _, opt := range strings.Split(opts, ",") {
+		if opt == "inline" {
+			return "", true
+		}
+	}
+
+	if name == "" {
+		name = strings.ToLower(sf.Name)
+	}
+	return name, false
+}
+
+func joinPath(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
@@ -0,0 +1,455 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pelletier/go-toml/v2/unstable"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/yaml.v3"
+)
+
+// Format is the syntax of a config document.
+type Format string
+
+const (
+	FormatYAML Format = "yaml"
+	FormatJSON Format = "json"
+	FormatTOML Format = "toml"
+	FormatEnv  Format = "env"
+)
+
+var formatByExt = map[string]Format{
+	".yaml": FormatYAML,
+	".yml":  FormatYAML,
+	".json": FormatJSON,
+	".toml": FormatTOML,
+	".env":  FormatEnv,
+}
+
+// ParseError is a syntax or type error at a position of a config document.
+// Line and Column start at 1 and are 0 when unknown; yaml syntax errors
+// have no Column, as yaml.v3 only reports their line.
+type ParseError struct {
+	Path   string
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	pos := e.Path
+	if e.Line > 0 {
+		pos += ":" + strconv.Itoa(e.Line)
+		if e.Column > 0 {
+			pos += ":" + strconv.Itoa(e.Column)
+		}
+	}
+	return pos + ": " + e.Err.Error()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+func (e *ParseError) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("path", e.Path)
+	if e.Line > 0 {
+		enc.AddInt("line", e.Line)
+		enc.AddInt("column", e.Column)
+	}
+	return nil
+}
+
+// document is a decoded config document: yaml nodes for structured formats,
+// applied in order, or env vars for .env files.
+type document struct {
+	name  string
+	nodes []*yaml.Node
+	env   map[string]string
+}
+
+func formatOf(path string) (Format, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if f, ok := formatByExt[ext]; ok {
+		return f, nil
+	}
+	if strings.HasPrefix(filepath.Base(path), ".env") {
+		return FormatEnv, nil
+	}
+	return "", fmt.Errorf("unsupported file format %q", ext)
+}
+
+func decodeDocument(name string, format Format, raw []byte) (*document, error) {
+	doc := &document{name: name}
+
+	var err error
+	switch format {
+	case FormatYAML:
+		doc.nodes, err = decodeYAML(name, raw)
+	case FormatJSON:
+		doc.nodes, err = decodeJSON(name, raw)
+	case FormatTOML:
+		doc.nodes, err = decodeTOML(name, raw)
+	case FormatEnv:
+		doc.env, err = decodeEnv(name, raw)
+	default:
+		err = fmt.Errorf("unsupported format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+var yamlLineRe = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// decodeYAML returns every document of a multi-document yaml stream.
+func decodeYAML(name string, raw []byte) ([]*yaml.Node, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(raw))
+
+	var nodes []*yaml.Node
+	for {
+		var node yaml.Node
+		err := dec.Decode(&node)
+		if errors.Is(err, io.EOF) {
+			return nodes, nil
+		}
+		if err != nil {
+			perr := &ParseError{Path: name, Err: err}
+			if m := yamlLineRe.FindStringSubmatch(err.Error()); m != nil {
+				perr.Line, _ = strconv.Atoi(m[1])
+				perr.Err = errors.New(m[2])
+			}
+			return nil, perr
+		}
+		nodes = append(nodes, &node)
+	}
+}
+
+// decodeJSON checks the syntax with encoding/json, which reports exact
+// offsets, and then reads the document as yaml to keep node positions.
+func decodeJSON(name string, raw []byte) ([]*yaml.Node, error) {
+	var v any
+	if err := json.Unmarshal(raw, &v); err != nil {
+		perr := &ParseError{Path: name, Err: err}
+
+		// Offset is the number of bytes read, including the offending one.
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			perr.Line, perr.Column = position(raw, int(syntaxErr.Offset)-1)
+		}
+		return nil, perr
+	}
+
+	return decodeYAML(name, raw)
+}
+
+// decodeTOML reads the document into a map and converts it to yaml nodes,
+// which then get the positions of the keys and values they came from.
+func decodeTOML(name string, raw []byte) ([]*yaml.Node, error) {
+	var m map[string]any
+	if err := toml.Unmarshal(raw, &m); err != nil {
+		perr := &ParseError{Path: name, Err: err}
+
+		var decErr *toml.DecodeError
+		if errors.As(err, &decErr) {
+			perr.Line, perr.Column = decErr.Position()
+			perr.Err = errors.New(strings.TrimPrefix(decErr.Error(), "toml: "))
+		}
+		return nil, perr
+	}
+
+	var node yaml.Node
+	if err := node.Encode(m); err != nil {
+		return nil, &ParseError{Path: name, Err: err}
+	}
+	setTOMLPositions(&node, "", tomlPositions(raw))
+	return []*yaml.Node{&node}, nil
+}
+
+// tomlPos is the line and column of a key and of its value.
+type tomlPos struct {
+	keyLine, keyColumn     int
+	valueLine, valueColumn int
+}
+
+// tomlPositions maps the paths of the keys of a valid document to their
+// positions. A path joins keys and [i] array indexes with NUL bytes.
+func tomlPositions(raw []byte) map[string]tomlPos {
+	t := &tomlScan{raw: raw, out: make(map[string]tomlPos), arrays: make(map[string]int)}
+	t.p.Reset(raw)
+
+	table := ""
+	for t.p.NextExpression() {
+		e := t.p.Expression()
+		switch e.Kind {
+		case unstable.Table:
+			table, _ = t.key("", e.Key(), true)
+		case unstable.ArrayTable:
+			path, _ := t.key("", e.Key(), true)
+			i := t.arrays[path]
+			t.arrays[path]++
+			table = tomlIndex(path, i)
+			t.record(table, t.out[path].keyLine, t.out[path].keyColumn)
+		case unstable.KeyValue:
+			t.keyValue(table, e)
+		}
+	}
+	return t.out
+}
+
+type tomlScan struct {
+	p      unstable.Parser
+	raw    []byte
+	out    map[string]tomlPos
+	arrays map[string]int
+}
+
+// key returns the path of the dotted key it under prefix and the offset
+// its last part ends at, and records the position of its parts. In table
+// headers, parts naming an array of tables refer to its last table.
+func (t *tomlScan) key(prefix string, it unstable.Iterator, header bool) (string, int) {
+	path, end := prefix, 0
+	for it.Next() {
+		n := it.Node()
+		path += "\x00" + string(n.Data)
+		line, col := position(t.raw, int(n.Raw.Offset))
+		t.record(path, line, col)
+		end = int(n.Raw.Offset + n.Raw.Length)
+		if n := t.arrays[path]; header && n > 0 && !it.IsLast() {
+			path = tomlIndex(path, n-1)
+		}
+	}
+	return path, end
+}
+
+// record keeps the first position seen for path, as a key seen again in
+// another table header refers to the same table.
+func (t *tomlScan) record(path string, line, col int) {
+	if _, ok := t.out[path]; !ok {
+		t.out[path] = tomlPos{keyLine: line, keyColumn: col, valueLine: line, valueColumn: col}
+	}
+}
+
+// keyValue records the positions of a key/value pair. The value starts
+// after the = following the key, as arrays carry no position of their own.
+func (t *tomlScan) keyValue(prefix string, kv *unstable.Node) {
+	path, off := t.key(prefix, kv.Key(), false)
+	for off < len(t.raw) && strings.IndexByte(" \t=", t.raw[off]) >= 0 {
+		off++
+	}
+
+	pos := t.out[path]
+	pos.valueLine, pos.valueColumn = position(t.raw, off)
+	t.out[path] = pos
+	t.value(path, kv.Value())
+}
+
+func (t *tomlScan) value(path string, v *unstable.Node) {
+	if v.Raw.Length > 0 {
+		pos := t.out[path]
+		pos.valueLine, pos.valueColumn = position(t.raw, int(v.Raw.Offset))
+		t.out[path] = pos
+	}
+
+	switch v.Kind {
+	case unstable.InlineTable:
+		for it := v.Children(); it.Next(); {
+			t.keyValue(path, it.Node())
+		}
+	case unstable.Array:
+		i := 0
+		for it := v.Children(); it.Next(); {
+			n := it.Node()
+			if n.Kind == unstable.Comment {
+				continue
+			}
+			elem := tomlIndex(path, i)
+			pos := t.out[path]
+			t.record(elem, pos.valueLine, pos.valueColumn)
+			t.value(elem, n)
+			i++
+		}
+	}
+}
+
+func tomlIndex(path string, i int) string {
+	return path + "\x00[" + strconv.Itoa(i) + "]"
+}
+
+// setTOMLPositions sets the positions of node, converted from a toml
+// document, and of its children.
+func setTOMLPositions(node *yaml.Node, path string, pos map[string]tomlPos) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			k, v := node.Content[i], node.Content[i+1]
+			child := path + "\x00" + k.Value
+			if p, ok := pos[child]; ok {
+				k.Line, k.Column = p.keyLine, p.keyColumn
+				v.Line, v.Column = p.valueLine, p.valueColumn
+			}
+			setTOMLPositions(v, child, pos)
+		}
+	case yaml.SequenceNode:
+		for i, v := range node.Content {
+			child := tomlIndex(path, i)
+			if p, ok := pos[child]; ok {
+				v.Line, v.Column = p.valueLine, p.valueColumn
+			}
+			setTOMLPositions(v, child, pos)
+		}
+	}
+}
+
+var envNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+// decodeEnv parses KEY=VALUE lines. Values may be single quoted (literal),
+// double quoted (with \n, \t, \" and \\ escapes) or bare, where a " #"
+// starts a comment.
+func decodeEnv(name string, raw []byte) (map[string]string, error) {
+	env := make(map[string]string)
+
+	for i, line := range strings.Split(string(raw), "\n") {
+		lineNo := i + 1
+		line = strings.TrimSuffix(line, "\r")
+
+		rest := strings.TrimLeft(line, " \t")
+		if rest == "" || strings.HasPrefix(rest, "#") {
+			continue
+		}
+		start := len(line) - len(rest)
+		if after, ok := strings.CutPrefix(rest, "export "); ok {
+			start += len(rest) - len(after)
+			rest = after
+		}
+
+		eq := strings.IndexByte(rest, '=')
+		if eq < 0 {
+			return nil, &ParseError{Path: name, Line: lineNo, Column: start + 1, Err: errors.New("expected KEY=VALUE")}
+		}
+		key := strings.TrimSpace(rest[:eq])
+		if !envNameRe.MatchString(key) {
+			return nil, &ParseError{Path: name, Line: lineNo, Column: start + 1, Err: fmt.Errorf("invalid variable name %q", key)}
+		}
+
+		rawValue := rest[eq+1:]
+		valueStart := start + eq + 1 + len(rawValue) - len(strings.TrimLeft(rawValue, " \t"))
+
+		value, err := parseEnvValue(strings.TrimSpace(rawValue))
+		if err != nil {
+			return nil, &ParseError{Path: name, Line: lineNo, Column: valueStart + 1, Err: err}
+		}
+		env[key] = value
+	}
+
+	return env, nil
+}
+
+func parseEnvValue(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, "'"):
+		end := strings.Index(value[1:], "'")
+		if end < 0 {
+			return "", errors.New("unterminated single quoted value")
+		}
+		return value[1 : end+1], nil
+	case strings.HasPrefix(value, `"`):
+		var b strings.Builder
+		for i := 1; i < len(value); i++ {
+			c := value[i]
+			switch {
+			case c == '"':
+				return b.String(), nil
+			case c == '\\' && i+1 < len(value):
+				i++
+				switch value[i] {
+				case 'n':
+					b.WriteByte('\n')
+				case 't':
+					b.WriteByte('\t')
+				default:
+					b.WriteByte(value[i])
+				}
+			default:
+				b.WriteByte(c)
+			}
+		}
+		return "", errors.New("unterminated double quoted value")
+	default:
+		if i := strings.Index(value, " #"); i >= 0 {
+			value = value[:i]
+		}
+		return strings.TrimSpace(value), nil
+	}
+}
+
+// position converts a byte offset of raw to a 1-based line and column.
+func position(raw []byte, offset int) (int, int) {
+	offset = max(0, min(offset, len(raw)))
+	before := raw[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	col := offset - bytes.LastIndexByte(before, '\n')
+	return line, col
+}
+
+// locateDecodeError finds the first value in node that cannot be decoded into
+// its field, to report the error at that value's position.
+func (l *loader) locateDecodeError(name string, node *yaml.Node, cause error) error {
+	if perr := l.findBadNode(name, node, ""); perr != nil {
+		return perr
+	}
+	return &ParseError{Path: name, Err: cause}
+}
+
+func (l *loader) findBadNode(name string, node *yaml.Node, prefix string) *ParseError {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, n := range node.Content {
+			if perr := l.findBadNode(name, n, prefix); perr != nil {
+				return perr
+			}
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := joinPath(prefix, node.Content[i].Value)
+			value := node.Content[i+1]
+
+			f, ok := l.byKey[key]
+			if !ok {
+				if perr := l.findBadNode(name, value, key); perr != nil {
+					return perr
+				}
+				continue
+			}
+
+			if err := value.Decode(reflect.New(f.value.Type()).Interface()); err != nil {
+				msg := err.Error()
+				var typeErr *yaml.TypeError
+				if errors.As(err, &typeErr) && len(typeErr.Errors) > 0 {
+					msg = typeErr.Errors[0]
+					if _, rest, ok := strings.Cut(msg, ": "); ok && strings.HasPrefix(msg, "line ") {
+						msg = rest
+					}
+				}
+				return &ParseError{
+					Path:   name,
+					Line:   value.Line,
+					Column: value.Column,
+					Err:    fmt.Errorf("%s -> %s", key, msg),
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package config_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/sangrita-tech/platform-go-pkg/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+type formatCfg struct {
+	Name    string        `yaml:"name" env:"FMT_NAME"`
+	Port    int           `yaml:"port" env:"FMT_PORT"`
+	Timeout time.Duration `yaml:"timeout" env:"FMT_TIMEOUT"`
+	Token   config.Secret `yaml:"token" env:"FMT_TOKEN"`
+	DB      struct {
+		Host string `yaml:"host" env:"FMT_DB_HOST"`
+	} `yaml:"db"`
+}
+
+func unsetFormatEnv() {
+	for _, env := range []string{"FMT_NAME", "FMT_PORT", "FMT_TIMEOUT", "FMT_TOKEN", "FMT_DB_HOST"} {
+		os.Unsetenv(env)
+	}
+}
+
+func loadFile(t *testing.T, name, content string) (formatCfg, string, error) {
+	t.Helper()
+	unsetFormatEnv()
+
+	path := writeTempFile(t, t.TempDir(), name, content)
+	cfg, err := config.LoadFrom[formatCfg]([]config.Source{config.File(path)})
+	return cfg, path, err
+}
+
+func requireParseError(t *testing.T, err error, path string, line, col int) *config.ParseError {
+	t.Helper()
+
+	var perr *config.ParseError
+	require.ErrorAs(t, err, &perr)
+	require.Equal(t, path, perr.Path)
+	require.Equal(t, line, perr.Line, perr.Error())
+	require.Equal(t, col, perr.Column, perr.Error())
+	return perr
+}
+
+func Test_LoadFrom_JSONFile_ReturnsCfg(t *testing.T) {
+	cfg, _, err := loadFile(t, "cfg.json", "{\n\t\"name\": \"json\",\n\t\"db\": {\"host\": \"db\"}\n}\n")
+
+	require.NoError(t, err)
+	require.Equal(t, "json", cfg.Name)
+	require.Equal(t, "db", cfg.DB.Host)
+}
+
+func Test_LoadFrom_JSONSyntaxError_ReportsPosition(t *testing.T) {
+	_, path, err := loadFile(t, "cfg.json", "{\n  \"name\": \"json\",\n  \"port\": ,\n}\n")
+
+	requireParseError(t, err, path, 3, 11)
+	require.ErrorContains(t, err, path+":3:11: ")
+}
+
+func Test_LoadFrom_YAMLMultiDocument_LaterDocumentWins(t *testing.T) {
+	cfg, _, err := loadFile(t, "cfg.yaml", "name: first\nport: 1\n---\nname: second\n")
+
+	require.NoError(t, err)
+	require.Equal(t, "second", cfg.Name)
+	require.Equal(t, 1, cfg.Port)
+}
+
+func Test_LoadFrom_YAMLTypeError_ReportsPositionOfValue(t *testing.T) {
+	_, path, err := loadFile(t, "cfg.yaml", "name: ok\ndb:\n  host: [a, b]\n")
+
+	perr := requireParseError(t, err, path, 3, 9)
+	require.ErrorContains(t, perr, "db.host -> cannot unmarshal")
+}
+
+func Test_LoadFrom_YAMLSyntaxError_ReportsLine(t *testing.T) {
+	_, path, err := loadFile(t, "cfg.yaml", "name: ok\nport: [\n")
+
+	var perr *config.ParseError
+	require.ErrorAs(t, err, &perr)
+	require.Equal(t, path, perr.Path)
+	require.Positive(t, perr.Line)
+}
+
+func Test_LoadFrom_TOMLFile_ReturnsCfgAndReportsErrors(t *testing.T) {
+	cfg, _, err := loadFile(t, "cfg.toml", "name = \"toml\"\ntimeout = \"3s\"\n[db]\nhost = \"db\"\n")
+	require.NoError(t, err)
+	require.Equal(t, "toml", cfg.Name)
+	require.Equal(t, 3*time.Second, cfg.Timeout)
+	require.Equal(t, "db", cfg.DB.Host)
+
+	_, path, err := loadFile(t, "bad.toml", "name = \"toml\"\nport = = 1\n")
+	perr := requireParseError(t, err, path, 2, 8)
+	require.NotContains(t, perr.Err.Error(), "toml:")
+}
+
+func Test_LoadFrom_TOMLTypeError_ReportsPositionOfValue(t *testing.T) {
+	_, path, err := loadFile(t, "cfg.toml", "name = \"toml\"\n\n[db]\n  host = [1, 2]\n")
+	perr := requireParseError(t, err, path, 4, 10)
+	require.ErrorContains(t, perr, path+":4:10: db.host -> cannot unmarshal")
+
+	_, path, err = loadFile(t, "cfg.toml", "name = \"toml\"\nport = \"x\"\n")
+	requireParseError(t, err, path, 2, 8)
+	require.ErrorContains(t, err, path+":2:8: port -> cannot unmarshal !!str `x` into int")
+}
+
+func Test_LoadFrom_TOMLStrictKeysAndDeprecation_ReportPositionOfKey(t *testing.T) {
+	path := writeTempFile(t, t.TempDir(), "cfg.toml", "name = \"x\"\nnmae = \"y\"\n\n[election]\nleaseDuraton = \"5s\"\n")
+	_, err := config.LoadFrom[keysCfg]([]config.Source{config.File(path)}, config.WithStrictKeys())
+	require.ErrorContains(t, err, path+`:2:1: unknown key "nmae"`)
+	require.ErrorContains(t, err, path+`:5:1: unknown key "election.leaseDuraton"`)
+
+	path = writeTempFile(t, t.TempDir(), "cfg.toml", "name = \"x\"\nelection.lease = \"5s\"\n")
+	_, err = config.LoadFrom[keysCfg]([]config.Source{config.File(path)}, config.WithVersion("v2.0.0"))
+	require.ErrorContains(t, err, path+":2:10: election.lease was removed in v2.0.0")
+}
+
+func Test_LoadFrom_TOMLArrayOfTables_ReportsPositionOfKey(t *testing.T) {
+	path := writeTempFile(t, t.TempDir(), "cfg.toml", "name = \"x\"\n\n[[extras]]\nid = 1\n\n[[extras]]\nid = 2\n\n[election]\nrenw = \"1s\"\n")
+
+	_, err := config.LoadFrom[keysCfg]([]config.Source{config.File(path)}, config.WithStrictKeys())
+
+	require.ErrorContains(t, err, path+`:3:3: unknown key "extras"`)
+	require.ErrorContains(t, err, path+`:10:1: unknown key "election.renw"`)
+}
+
+func Test_LoadFrom_DotEnvFile_SetsFieldsWithoutTouchingEnv(t *testing.T) {
+	cfg, path, err := loadFile(t, "app.env", strings.Join([]string{
+		"# comment",
+		"export FMT_NAME=dotenv # trailing comment",
+		`FMT_DB_HOST="db\thost"`,
+		"FMT_TIMEOUT='2s'",
+		"UNRELATED=1",
+		"",
+	}, "\n"))
+
+	require.NoError(t, err)
+	require.Equal(t, "dotenv", cfg.Name)
+	require.Equal(t, "db\thost", cfg.DB.Host)
+	require.Equal(t, 2*time.Second, cfg.Timeout)
+	_, set := os.LookupEnv("FMT_NAME")
+	require.False(t, set)
+
+	_, tree, err := config.LoadEffective[formatCfg]([]config.Source{config.File(path)})
+	require.NoError(t, err)
+	require.Equal(t, config.Origin{Source: "file " + path, Key: "FMT_NAME"}, *findNode(t, tree, "name").Origin)
+}
+
+func Test_LoadFrom_DotEnvSyntaxError_ReportsPosition(t *testing.T) {
+	_, path, err := loadFile(t, ".env", "FMT_NAME=ok\n  FMT_PORT=\"12\n")
+
+	requireParseError(t, err, path, 2, 12)
+}
+
+func Test_LoadFrom_Reader_ReadsGivenFormat(t *testing.T) {
+	unsetFormatEnv()
+
+	cfg, err := config.LoadFrom[formatCfg]([]config.Source{
+		config.Reader(strings.NewReader(`{"name": "from-reader", "port": 1}`), config.FormatJSON),
+		config.Reader(strings.NewReader("FMT_PORT=2\n"), config.FormatEnv),
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, "from-reader", cfg.Name)
+	require.Equal(t, 2, cfg.Port)
+}
+
+func Test_LoadFrom_FS_ReadsEmbeddedDefaults(t *testing.T) {
+	unsetFormatEnv()
+	t.Setenv("FMT_PORT", "3")
+	fsys := fstest.MapFS{
+		"defaults/app.yaml": {Data: []byte("name: embedded\nport: 1\n")},
+	}
+
+	cfg, err := config.LoadFrom[formatCfg]([]config.Source{
+		config.FS(fsys, "defaults/app.yaml"),
+		config.FS(fsys, "defaults/missing.yaml"),
+		config.Env(),
+	})
+	require.NoError(t, err)
+	require.Equal(t, "embedded", cfg.Name)
+	require.Equal(t, 3, cfg.Port)
+
+	_, err = config.LoadFrom[formatCfg]([]config.Source{config.FS(fsys, "defaults/missing.yaml")}, config.WithStrictFiles())
+	require.Error(t, err)
+}
@@ -0,0 +1,157 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sangrita-tech/platform-go-pkg/pkg/logger"
+	"github.com/sangrita-tech/platform-go-pkg/pkg/version"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+)
+
+// Deprecation tags. deprecated-key lists old yaml keys in the same mapping
+// as the field, deprecated-env old env var names and deprecated-until the
+// version from which using an old name is an error rather than a warning:
+//
+//	LeaseDuration time.Duration `yaml:"leaseDuration" env:"LEASE_DURATION" deprecated-key:"lease" deprecated-env:"LEASE" deprecated-until:"v2.0.0"`
+const (
+	tagDeprecatedKey   = "deprecated-key"
+	tagDeprecatedEnv   = "deprecated-env"
+	tagDeprecatedUntil = "deprecated-until"
+)
+
+// checkKeys reports every mapping key of node that does not lead to a field.
+func (l *loader) checkKeys(name string, node *yaml.Node, prefix string) []error {
+	var errs []error
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, n := range node.Content {
+			errs = append(errs, l.checkKeys(name, n, prefix)...)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			k := node.Content[i]
+			key := joinPath(prefix, k.Value)
+
+			switch {
+			case l.byKey[key] != nil:
+			case l.prefixes[key]:
+				errs = append(errs, l.checkKeys(name, node.Content[i+1], key)...)
+			default:
+				errs = append(errs, &ParseError{
+					Path:   name,
+					Line:   k.Line,
+					Column: k.Column,
+					Err:    fmt.Errorf("unknown key %q", key),
+				})
+			}
+		}
+	}
+
+	return errs
+}
+
+// renameDeprecated rewrites deprecated keys of node to the keys of their
+// fields. When both are set the new key wins.
+func (l *loader) renameDeprecated(name, source string, node *yaml.Node, prefix string) error {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, n := range node.Content {
+			if err := l.renameDeprecated(name, source, n, prefix); err != nil {
+				return err
+			}
+		}
+	case yaml.MappingNode:
+		present := make(map[string]bool, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			present[node.Content[i].Value] = true
+		}
+
+		content := node.Content[:0]
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			k, v := node.Content[i], node.Content[i+1]
+			key := joinPath(prefix, k.Value)
+
+			if f, ok := l.deprecatedKeys[key]; ok {
+				if err := l.deprecate(f, key, f.key, source); err != nil {
+					return &ParseError{Path: name, Line: k.Line, Column: k.Column, Err: err}
+				}
+
+				newName := f.key[strings.LastIndexByte(f.key, '.')+1:]
+				if present[newName] {
+					continue
+				}
+				k.Value = newName
+			} else if l.prefixes[key] {
+				if err := l.renameDeprecated(name, source, v, key); err != nil {
+					return err
+				}
+			}
+
+			content = append(content, k, v)
+		}
+		node.Content = content
+	}
+
+	return nil
+}
+
+// lookupDeprecatedEnv reads f from its deprecated env vars.
+func (l *loader) lookupDeprecatedEnv(f *field, source string, lookup func(string) (string, bool)) (string, string, bool, error) {
+	for _, env := range f.deprecatedEnvs {
+		raw, ok := lookup(env)
+		if !ok {
+			continue
+		}
+
+		use := ""
+		if len(f.envs) > 0 {
+			use = f.envs[0]
+		}
+		if err := l.deprecate(f, env, use, source); err != nil {
+			return "", "", false, fmt.Errorf("env %s -> %w", env, err)
+		}
+		return env, raw, true, nil
+	}
+	return "", "", false, nil
+}
+
+// deprecate logs a warning about old being used instead of use, or returns
+// an error once the running version reached the field's deprecated-until.
+func (l *loader) deprecate(f *field, old, use, source string) error {
+	if f.deprecatedUntil != "" {
+		until, err := utilversion.ParseGeneric(f.deprecatedUntil)
+		if err != nil {
+			return fmt.Errorf("field %s -> invalid %s tag -> %w", f.name, tagDeprecatedUntil, err)
+		}
+
+		// Development builds ("dev") never fail.
+		current, err := utilversion.ParseGeneric(l.opts.version)
+		if err == nil && current.AtLeast(until) {
+			return fmt.Errorf("%s was removed in %s, use %s", old, f.deprecatedUntil, use)
+		}
+	}
+
+	log := l.opts.logger
+	if log == nil {
+		log = logger.L()
+	}
+	log.Warn("deprecated config key",
+		zap.String("source", source),
+		zap.String("key", old),
+		zap.String("use", use),
+		zap.String("removedIn", f.deprecatedUntil),
+	)
+	return nil
+}
+
+func defaultOptions() options {
+	return options{
+		ctx:     context.Background(),
+		version: version.Version,
+	}
+}
@@ -0,0 +1,106 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sangrita-tech/platform-go-pkg/pkg/config"
+	"github.com/sangrita-tech/platform-go-pkg/pkg/logger"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type electionCfg struct {
+	LeaseDuration time.Duration `yaml:"leaseDuration" env:"KEYS_LEASE_DURATION" deprecated-key:"lease" deprecated-env:"KEYS_LEASE" deprecated-until:"v2.0.0"`
+	RenewDeadline time.Duration `yaml:"renewDeadline" deprecated-key:"renew"`
+}
+
+type keysCfg struct {
+	Name     string            `yaml:"name"`
+	Labels   map[string]string `yaml:"labels"`
+	Election electionCfg       `yaml:"election"`
+}
+
+func loadKeys(t *testing.T, content string, opts ...config.Option) (keysCfg, error) {
+	t.Helper()
+
+	path := writeTempFile(t, t.TempDir(), "cfg.yaml", content)
+	return config.LoadFrom[keysCfg]([]config.Source{config.File(path), config.Env()}, opts...)
+}
+
+func Test_LoadFrom_UnknownKey_IgnoredByDefault(t *testing.T) {
+	cfg, err := loadKeys(t, "name: x\nelection:\n  leaseDuraton: 5s\n")
+
+	require.NoError(t, err)
+	require.Equal(t, "x", cfg.Name)
+	require.Zero(t, cfg.Election.LeaseDuration)
+}
+
+func Test_LoadFrom_StrictKeys_ReportsEveryUnknownKey(t *testing.T) {
+	path := writeTempFile(t, t.TempDir(), "cfg.yaml", "name: x\nnmae: y\nlabels:\n  any: key\nelection:\n  leaseDuraton: 5s\n")
+
+	_, err := config.LoadFrom[keysCfg]([]config.Source{config.File(path)}, config.WithStrictKeys())
+
+	var perr *config.ParseError
+	require.ErrorAs(t, err, &perr)
+	require.ErrorContains(t, err, path+`:2:1: unknown key "nmae"`)
+	require.ErrorContains(t, err, path+`:6:3: unknown key "election.leaseDuraton"`)
+	require.NotContains(t, err.Error(), "labels")
+}
+
+func Test_LoadFrom_DeprecatedKey_MapsToFieldAndWarns(t *testing.T) {
+	core, logs := observer.New(zap.WarnLevel)
+
+	cfg, err := loadKeys(t, "election:\n  lease: 5s\n  renew: 2s\n  renewDeadline: 3s\n",
+		config.WithStrictKeys(), config.WithLogger(zap.New(core)), config.WithVersion("v1.4.0"))
+
+	require.NoError(t, err)
+	require.Equal(t, 5*time.Second, cfg.Election.LeaseDuration)
+	require.Equal(t, 3*time.Second, cfg.Election.RenewDeadline)
+	require.Equal(t, 2, logs.Len())
+	require.Equal(t, "election.lease", logs.All()[0].ContextMap()["key"])
+	require.Equal(t, "election.leaseDuration", logs.All()[0].ContextMap()["use"])
+}
+
+func Test_LoadFrom_DeprecatedEnv_MapsToFieldAndWarns(t *testing.T) {
+	t.Setenv("KEYS_LEASE", "7s")
+	core, logs := observer.New(zap.WarnLevel)
+
+	cfg, err := loadKeys(t, "name: x\n", config.WithLogger(zap.New(core)), config.WithVersion("dev"))
+
+	require.NoError(t, err)
+	require.Equal(t, 7*time.Second, cfg.Election.LeaseDuration)
+	require.Equal(t, 1, logs.Len())
+	require.Equal(t, "KEYS_LEASE_DURATION", logs.All()[0].ContextMap()["use"])
+}
+
+func Test_LoadFrom_DeprecatedKeyWithoutLogger_WarnsToGlobalLogger(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	_, cleanup, err := logger.New(&logger.Config{
+		Level:   "info",
+		Format:  "json",
+		Outputs: []logger.OutputConfig{{Type: logger.OutputFile, Path: path}},
+	})
+	require.NoError(t, err)
+
+	_, err = loadKeys(t, "election:\n  lease: 5s\n", config.WithVersion("dev"))
+	require.NoError(t, err)
+	cleanup()
+
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(raw), `"msg":"deprecated config key"`)
+	require.Contains(t, string(raw), `"key":"election.lease"`)
+}
+
+func Test_LoadFrom_DeprecatedAfterUntil_ReturnsError(t *testing.T) {
+	_, err := loadKeys(t, "election:\n  lease: 5s\n", config.WithVersion("v2.0.0"))
+	require.ErrorContains(t, err, "election.lease was removed in v2.0.0, use election.leaseDuration")
+
+	t.Setenv("KEYS_LEASE", "7s")
+	_, err = loadKeys(t, "name: x\n", config.WithVersion("v2.1.0"))
+	require.ErrorContains(t, err, "env KEYS_LEASE -> KEYS_LEASE was removed in v2.0.0, use KEYS_LEASE_DURATION")
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	informers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	kindConfigMap = "configmap"
+	kindSecret    = "secret"
+)
+
+// kubeReadTimeout bounds reading a ConfigMap or Secret, so an unreachable
+// API server fails the load instead of blocking it.
+const kubeReadTimeout = 30 * time.Second
+
+type kubeSource struct {
+	cs        kubernetes.Interface
+	kind      string
+	namespace string
+	name      string
+	key       string
+}
+
+// ConfigMap reads one key of a ConfigMap through the API, for example with a
+// clientset from kube.New. The format is picked by the key's extension and
+// defaults to yaml. A missing ConfigMap or key is skipped unless
+// WithStrictFiles is used. The read is canceled with the context given to
+// WithContext and times out after 30 seconds. A Watcher reloads it through
+// an informer.
+func ConfigMap(cs kubernetes.Interface, namespace, name, key string) Source {
+	return kubeSource{cs: cs, kind: kindConfigMap, namespace: namespace, name: name, key: key}
+}
+
+// KubeSecret reads one key of a Secret the same way ConfigMap does.
+func KubeSecret(cs kubernetes.Interface, namespace, name, key string) Source {
+	return kubeSource{cs: cs, kind: kindSecret, namespace: namespace, name: name, key: key}
+}
+
+func (s kubeSource) String() string {
+	return fmt.Sprintf("%s %s/%s[%s]", s.kind, s.namespace, s.name, s.key)
+}
+
+func (s kubeSource) apply(l *loader) error {
+	ctx, cancel := context.WithTimeout(l.opts.ctx, kubeReadTimeout)
+	defer cancel()
+
+	raw, found, err := s.read(ctx)
+	if err != nil {
+		return err
+	}
+	if !found {
+		if l.opts.strictFiles {
+			return fmt.Errorf("%s not found", s)
+		}
+		return nil
+	}
+
+	format, err := formatOf(s.key)
+	if err != nil {
+		format = FormatYAML
+	}
+
+	doc, err := decodeDocument(s.String(), format, raw)
+	if err != nil {
+		return err
+	}
+	return l.applyDocument(s.String(), doc)
+}
+
+func (s kubeSource) read(ctx context.Context) ([]byte, bool, error) {
+	switch s.kind {
+	case kindSecret:
+		sec, err := s.cs.CoreV1().Secrets(s.namespace).Get(ctx, s.name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return nil, false, nil
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		raw, ok := sec.Data[s.key]
+		return raw, ok, nil
+	default:
+		cm, err := s.cs.CoreV1().ConfigMaps(s.namespace).Get(ctx, s.name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return nil, false, nil
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		if v, ok := cm.Data[s.key]; ok {
+			return []byte(v), true, nil
+		}
+		raw, ok := cm.BinaryData[s.key]
+		return raw, ok, nil
+	}
+}
+
+func (s kubeSource) watch(ctx context.Context, notify func()) error {
+	tweak := func(o *metav1.ListOptions) {
+		o.FieldSelector = fields.OneTermEqualSelector("metadata.name", s.name).String()
+	}
+
+	var informer cache.SharedIndexInformer
+	switch s.kind {
+	case kindSecret:
+		informer = informers.NewFilteredSecretInformer(s.cs, s.namespace, 0, cache.Indexers{}, tweak)
+	default:
+		informer = informers.NewFilteredConfigMapInformer(s.cs, s.namespace, 0, cache.Indexers{}, tweak)
+	}
+
+	onEvent := func(obj any) {
+		if d, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+			obj = d.Obj
+		}
+		if m, ok := obj.(metav1.Object); ok && m.GetName() == s.name {
+			notify()
+		}
+	}
+
+	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    onEvent,
+		UpdateFunc: func(_, obj any) { onEvent(obj) },
+		DeleteFunc: onEvent,
+	})
+	if err != nil {
+		return err
+	}
+
+	informer.Run(ctx.Done())
+	return nil
+}
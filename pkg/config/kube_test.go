@@ -0,0 +1,117 @@
+package config_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sangrita-tech/platform-go-pkg/pkg/config"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+)
+
+type kubeCfg struct {
+	Name     string        `yaml:"name"`
+	Port     int           `yaml:"port"`
+	Password config.Secret `yaml:"password"`
+}
+
+func newConfigMap(data string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+		Data:       map[string]string{"app.yaml": data},
+	}
+}
+
+func Test_LoadFrom_ConfigMapAndSecret_ReturnsCfg(t *testing.T) {
+	cs := fake.NewClientset(
+		newConfigMap("name: from-cm\nport: 8080\n"),
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+			Data:       map[string][]byte{"secret.json": []byte(`{"password": "hunter2"}`)},
+		},
+	)
+
+	cfg, err := config.LoadFrom[kubeCfg]([]config.Source{
+		config.ConfigMap(cs, "default", "app", "app.yaml"),
+		config.KubeSecret(cs, "default", "app", "secret.json"),
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, "from-cm", cfg.Name)
+	require.Equal(t, 8080, cfg.Port)
+	require.Equal(t, "hunter2", cfg.Password.Value())
+}
+
+func Test_LoadFrom_MissingConfigMap_SkippedUnlessStrict(t *testing.T) {
+	cs := fake.NewClientset(newConfigMap("name: x\n"))
+	sources := []config.Source{
+		config.ConfigMap(cs, "default", "missing", "app.yaml"),
+		config.ConfigMap(cs, "default", "app", "missing.yaml"),
+	}
+
+	_, err := config.LoadFrom[kubeCfg](sources)
+	require.NoError(t, err)
+
+	_, err = config.LoadFrom[kubeCfg](sources, config.WithStrictFiles())
+	require.ErrorContains(t, err, "configmap default/missing[app.yaml] not found")
+}
+
+func Test_LoadFrom_ConfigMapParseError_ReportsSource(t *testing.T) {
+	cs := fake.NewClientset(newConfigMap("name: x\nport: nope\n"))
+
+	_, err := config.LoadFrom[kubeCfg]([]config.Source{config.ConfigMap(cs, "default", "app", "app.yaml")})
+
+	var perr *config.ParseError
+	require.ErrorAs(t, err, &perr)
+	require.Equal(t, "configmap default/app[app.yaml]", perr.Path)
+	require.Equal(t, 2, perr.Line)
+}
+
+func Test_Watcher_ConfigMapUpdated_PublishesNewCfg(t *testing.T) {
+	cs := fake.NewClientset(newConfigMap("name: v1\n"))
+
+	w, err := config.NewSourceWatcher[kubeCfg]([]config.Source{config.ConfigMap(cs, "default", "app", "app.yaml")}, nil)
+	require.NoError(t, err)
+	require.Equal(t, "v1", w.Get().Name)
+
+	published := make(chan kubeCfg, 10)
+	w.Subscribe(func(_, new kubeCfg) {
+		published <- new
+	})
+	runWatcher(t, w)
+
+	_, err = cs.CoreV1().ConfigMaps("default").Update(context.Background(), newConfigMap("name: v2\n"), metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	select {
+	case c := <-published:
+		require.Equal(t, "v2", c.Name)
+	case <-time.After(5 * time.Second):
+		t.Fatal("updated config map not published")
+	}
+}
+
+func Test_LoadFrom_ConfigMapUnreachable_FailsWithContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+	cs, err := kubernetes.NewForConfig(&rest.Config{Host: srv.URL})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = config.LoadFrom[kubeCfg]([]config.Source{config.ConfigMap(cs, "default", "app", "app.yaml")}, config.WithContext(ctx))
+
+	require.ErrorContains(t, err, "failed to load configmap default/app[app.yaml]")
+	require.Less(t, time.Since(start), 5*time.Second)
+}
@@ -0,0 +1,94 @@
+package config_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sangrita-tech/platform-go-pkg/pkg/config"
+	"github.com/sangrita-tech/platform-go-pkg/pkg/events"
+	"github.com/sangrita-tech/platform-go-pkg/pkg/healthcheck"
+	"github.com/sangrita-tech/platform-go-pkg/pkg/kube"
+	"github.com/sangrita-tech/platform-go-pkg/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+type serviceCfg struct {
+	Timeout time.Duration      `yaml:"timeout" env:"TIMEOUT" env-default:"1s"`
+	Kube    kube.Config        `yaml:"kube" env-prefix:"KUBE_"`
+	Health  healthcheck.Config `yaml:"health" env-prefix:"HEALTH_"`
+}
+
+type controllerCfg struct {
+	Kube   kube.Config   `yaml:"kube"`
+	Events events.Config `yaml:"events"`
+}
+
+type collidingCfg struct {
+	Timeout time.Duration `yaml:"timeout" env:"TIMEOUT"`
+	Kube    kube.Config   `yaml:"kube"`
+}
+
+func Test_LoadFrom_EnvPrefixTags_NamespaceComposedConfigs(t *testing.T) {
+	t.Setenv("TIMEOUT", "2s")
+	t.Setenv("KUBE_TIMEOUT", "45s")
+	t.Setenv("KUBE_QPS", "50")
+	t.Setenv("HEALTH_ADDR", ":9000")
+
+	cfg, err := config.LoadFrom[serviceCfg]([]config.Source{config.Defaults(), config.Env()})
+
+	require.NoError(t, err)
+	require.Equal(t, 2*time.Second, cfg.Timeout)
+	require.Equal(t, 45*time.Second, cfg.Kube.Timeout)
+	require.Equal(t, float32(50), cfg.Kube.QPS)
+	require.Equal(t, ":9000", cfg.Health.Addr)
+}
+
+func Test_LoadFrom_GlobalEnvPrefix_PrependedToAllNames(t *testing.T) {
+	t.Setenv("TIMEOUT", "2s")
+	t.Setenv("KUBE_TIMEOUT", "2s")
+	t.Setenv("MYAPP_TIMEOUT", "3s")
+	t.Setenv("MYAPP_KUBE_TIMEOUT", "4s")
+
+	cfg, err := config.LoadFrom[serviceCfg](
+		[]config.Source{config.Defaults(), config.Env()},
+		config.WithEnvPrefix("MYAPP_"),
+	)
+
+	require.NoError(t, err)
+	require.Equal(t, 3*time.Second, cfg.Timeout)
+	require.Equal(t, 4*time.Second, cfg.Kube.Timeout)
+}
+
+func Test_LoadFrom_EnvCollision_ReturnsError(t *testing.T) {
+	_, err := config.LoadFrom[collidingCfg]([]config.Source{config.Defaults(), config.Env()})
+
+	require.ErrorContains(t, err, "TIMEOUT used by Timeout, Kube.Timeout")
+}
+
+func Test_LoadFrom_EnvCollisionWithoutEnvSource_Loads(t *testing.T) {
+	_, err := config.LoadFrom[collidingCfg]([]config.Source{config.Defaults(), config.Reader(strings.NewReader("{}"), config.FormatYAML)})
+
+	require.NoError(t, err)
+}
+
+func Test_LoadFrom_EventsConfigWithoutPrefix_DoesNotCollide(t *testing.T) {
+	t.Setenv("EVENTS_QPS", "0.5")
+
+	cfg, err := config.LoadFrom[controllerCfg]([]config.Source{config.Defaults(), config.Env()})
+
+	require.NoError(t, err)
+	require.Equal(t, float32(0.5), cfg.Events.QPS)
+	require.Equal(t, float32(20), cfg.Kube.QPS)
+}
+
+func Test_LoadFrom_LoggerTraceKeys_ReadFromTracePrefixedEnv(t *testing.T) {
+	t.Setenv("TRACE_ID_KEY", "trace.id")
+	t.Setenv("TRACE_SPAN_ID_KEY", "span.id")
+
+	cfg, err := config.LoadFrom[logger.Config]([]config.Source{config.Defaults(), config.Env()})
+
+	require.NoError(t, err)
+	require.Equal(t, "trace.id", cfg.Trace.TraceIDKey)
+	require.Equal(t, "span.id", cfg.Trace.SpanIDKey)
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+const (
+	redacted         = "[REDACTED]"
+	secretFilePrefix = "file://"
+	secretFileEnv    = "_FILE"
+)
+
+var secretType = reflect.TypeOf(Secret{})
+
+// Secret holds a sensitive value that never shows up in logs or dumps: it
+// prints, marshals to JSON/YAML and logs through zap as [REDACTED].
+//
+// A value of the form file:///path is read from that file, and for env vars
+// NAME_FILE=/path is accepted in place of NAME, so Kubernetes-mounted secrets
+// can be used directly. Such secrets are re-read when a Watcher sees the
+// file change.
+type Secret struct {
+	value string
+	path  string
+}
+
+func NewSecret(value string) Secret {
+	return Secret{value: value}
+}
+
+// Value returns the plain secret.
+func (s Secret) Value() string {
+	return s.value
+}
+
+// Path returns the file the secret was read from, if any.
+func (s Secret) Path() string {
+	return s.path
+}
+
+func (s Secret) String() string {
+	if s.value == "" {
+		return ""
+	}
+	return redacted
+}
+
+func (s Secret) GoString() string {
+	return fmt.Sprintf("config.Secret(%q)", s.String())
+}
+
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf("%q", s.String())), nil
+}
+
+func (s Secret) MarshalYAML() (any, error) {
+	return s.String(), nil
+}
+
+func (s *Secret) UnmarshalText(text []byte) error {
+	raw := string(text)
+
+	path, ok := strings.CutPrefix(raw, secretFilePrefix)
+	if !ok {
+		*s = Secret{value: raw}
+		return nil
+	}
+
+	value, err := readSecretFile(path)
+	if err != nil {
+		return err
+	}
+	*s = Secret{value: value, path: path}
+	return nil
+}
+
+func readSecretFile(path string) (string, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file -> %w", err)
+	}
+	return strings.TrimRight(string(raw), "\r\n"), nil
+}
+
+// secretFiles returns the files the secrets of cfg were read from.
+func secretFiles(cfg any) []string {
+	var paths []string
+	for _, f := range collectFields(reflect.ValueOf(cfg), "") {
+		if f.value.Type() != secretType {
+			continue
+		}
+		if p := f.value.Interface().(Secret).path; p != "" {
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
@@ -0,0 +1,120 @@
+package config_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sangrita-tech/platform-go-pkg/pkg/config"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/yaml.v3"
+)
+
+type secretCfg struct {
+	User     string        `yaml:"user" env:"SECRET_DB_USER"`
+	Password config.Secret `yaml:"password" env:"SECRET_DB_PASSWORD"`
+}
+
+func unsetSecretEnv() {
+	for _, env := range []string{"SECRET_DB_USER", "SECRET_DB_PASSWORD", "SECRET_DB_PASSWORD_FILE"} {
+		os.Unsetenv(env)
+	}
+}
+
+func Test_Secret_Redacted(t *testing.T) {
+	cfg := secretCfg{User: "app", Password: config.NewSecret("hunter2")}
+
+	require.Equal(t, "hunter2", cfg.Password.Value())
+	require.NotContains(t, fmt.Sprintf("%v %+v %#v %s", cfg, cfg, cfg, cfg.Password), "hunter2")
+
+	js, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	require.NotContains(t, string(js), "hunter2")
+
+	ys, err := yaml.Marshal(cfg)
+	require.NoError(t, err)
+	require.NotContains(t, string(ys), "hunter2")
+
+	var buf bytes.Buffer
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(&buf), zapcore.DebugLevel)
+	zap.New(core).Info("connect", zap.Any("password", cfg.Password), zap.Any("cfg", cfg))
+	require.NotContains(t, buf.String(), "hunter2")
+	require.Contains(t, buf.String(), "[REDACTED]")
+}
+
+func Test_Load_SecretFromEnv_ReturnsValue(t *testing.T) {
+	unsetSecretEnv()
+	t.Setenv("SECRET_DB_PASSWORD", "from-env")
+
+	cfg, err := config.Load[secretCfg]("")
+
+	require.NoError(t, err)
+	require.Equal(t, "from-env", cfg.Password.Value())
+	require.Empty(t, cfg.Password.Path())
+}
+
+func Test_Load_SecretFromFileEnv_ReadsFile(t *testing.T) {
+	unsetSecretEnv()
+	secretPath := writeTempFile(t, t.TempDir(), "password", "from-file\n")
+	t.Setenv("SECRET_DB_PASSWORD_FILE", secretPath)
+
+	cfg, err := config.Load[secretCfg]("")
+
+	require.NoError(t, err)
+	require.Equal(t, "from-file", cfg.Password.Value())
+	require.Equal(t, secretPath, cfg.Password.Path())
+}
+
+func Test_Load_SecretFileReferenceInYAML_ReadsFile(t *testing.T) {
+	unsetSecretEnv()
+	dir := t.TempDir()
+	secretPath := writeTempFile(t, dir, "password", "from-ref")
+	path := writeTempFile(t, dir, "cfg.yaml", "user: app\npassword: file://"+secretPath+"\n")
+
+	cfg, err := config.Load[secretCfg](path)
+
+	require.NoError(t, err)
+	require.Equal(t, "from-ref", cfg.Password.Value())
+}
+
+func Test_Load_SecretFileMissing_ReturnsError(t *testing.T) {
+	unsetSecretEnv()
+	t.Setenv("SECRET_DB_PASSWORD_FILE", filepath.Join(t.TempDir(), "nope"))
+
+	_, err := config.Load[secretCfg]("")
+
+	require.Error(t, err)
+}
+
+func Test_Watcher_SecretFileRotated_PublishesNewSecret(t *testing.T) {
+	unsetSecretEnv()
+	secretDir := t.TempDir()
+	secretPath := writeTempFile(t, secretDir, "password", "v1")
+	t.Setenv("SECRET_DB_PASSWORD_FILE", secretPath)
+	path := writeTempFile(t, t.TempDir(), "cfg.yaml", "user: app\n")
+
+	w, err := config.NewWatcher[secretCfg](path, nil)
+	require.NoError(t, err)
+	require.Equal(t, "v1", w.Get().Password.Value())
+
+	published := make(chan secretCfg, 10)
+	w.Subscribe(func(_, new secretCfg) {
+		published <- new
+	})
+	runWatcher(t, w)
+
+	writeTempFile(t, secretDir, "password", "v2")
+
+	select {
+	case c := <-published:
+		require.Equal(t, "v2", c.Password.Value())
+	case <-time.After(5 * time.Second):
+		t.Fatal("rotated secret not published")
+	}
+}
@@ -0,0 +1,249 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// Source provides configuration values. Sources passed to LoadFrom are
+// applied in order, so each one overrides the values set by the sources
+// before it.
+//
+// File based sources address fields by their yaml keys, whatever the file
+// format. Env and flag sources set single fields from their string form.
+type Source interface {
+	String() string
+	apply(l *loader) error
+}
+
+type defaultsSource struct{}
+
+// Defaults sets every field to its env-default tag value.
+func Defaults() Source {
+	return defaultsSource{}
+}
+
+func (defaultsSource) String() string { return "defaults" }
+
+func (defaultsSource) apply(l *loader) error {
+	for _, f := range l.fields {
+		if f.def == nil {
+			continue
+		}
+		if err := l.set(f, *f.def, Origin{Source: "defaults"}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type envSource struct{}
+
+// Env sets fields from the environment variables named in their env tags.
+func Env() Source {
+	return envSource{}
+}
+
+func (envSource) String() string { return "env" }
+
+func isEnv(src Source) bool {
+	_, ok := src.(envSource)
+	return ok
+}
+
+func (envSource) apply(l *loader) error {
+	return l.applyEnv("env", os.LookupEnv)
+}
+
+func lookupEnv(f *field, lookup func(string) (string, bool)) (string, string, bool) {
+	for _, env := range f.envs {
+		if raw, ok := lookup(env); ok {
+			return env, raw, true
+		}
+	}
+
+	if f.value.Type() == secretType {
+		for _, env := range f.envs {
+			if path, ok := lookup(env + secretFileEnv); ok {
+				return env + secretFileEnv, secretFilePrefix + path, true
+			}
+		}
+	}
+
+	return "", "", false
+}
+
+type fileSource struct {
+	path string
+}
+
+// File reads a yaml, json, toml or .env file, picked by its extension.
+// A missing file is skipped unless WithStrictFiles is used.
+func File(path string) Source {
+	return fileSource{path: path}
+}
+
+func (s fileSource) String() string { return "file " + s.path }
+
+func (s fileSource) apply(l *loader) error {
+	raw, err := os.ReadFile(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) && !l.opts.strictFiles {
+			return nil
+		}
+		return err
+	}
+
+	return l.applyRaw(s.String(), s.path, raw)
+}
+
+type fsSource struct {
+	fsys fs.FS
+	path string
+}
+
+// FS reads a file from fsys like File does, for example defaults embedded
+// with go:embed.
+func FS(fsys fs.FS, path string) Source {
+	return fsSource{fsys: fsys, path: path}
+}
+
+func (s fsSource) String() string { return "fs " + s.path }
+
+func (s fsSource) apply(l *loader) error {
+	raw, err := fs.ReadFile(s.fsys, s.path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) && !l.opts.strictFiles {
+			return nil
+		}
+		return err
+	}
+
+	return l.applyRaw(s.String(), s.path, raw)
+}
+
+type readerSource struct {
+	r      io.Reader
+	format Format
+}
+
+// Reader reads a document in the given format from r.
+func Reader(r io.Reader, format Format) Source {
+	return readerSource{r: r, format: format}
+}
+
+func (s readerSource) String() string { return "reader " + string(s.format) }
+
+func (s readerSource) apply(l *loader) error {
+	raw, err := io.ReadAll(s.r)
+	if err != nil {
+		return err
+	}
+
+	doc, err := decodeDocument(s.String(), s.format, raw)
+	if err != nil {
+		return err
+	}
+	return l.applyDocument(s.String(), doc)
+}
+
+type dirSource struct {
+	path string
+}
+
+// Dir reads every yaml, json, toml and .env file in a directory in lexical order,
+// so fragments can be prefixed to control their precedence (10-base.yaml,
+// 20-overlay.yaml). A missing directory is skipped unless WithStrictFiles
+// is used.
+func Dir(path string) Source {
+	return dirSource{path: path}
+}
+
+func (s dirSource) String() string { return "dir " + s.path }
+
+func (s dirSource) apply(l *loader) error {
+	entries, err := os.ReadDir(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) && !l.opts.strictFiles {
+			return nil
+		}
+		return err
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() || strings.HasPrefix(e.Name(), "..") {
+			continue
+		}
+		if _, err := formatOf(e.Name()); err == nil {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := File(filepath.Join(s.path, name)).apply(l); err != nil {
+			return fmt.Errorf("%s -> %w", name, err)
+		}
+	}
+	return nil
+}
+
+type flagSource struct {
+	fs *pflag.FlagSet
+}
+
+// Flags sets fields from the flags of fs that were set on the command line.
+// A field matches the flag named after its dotted yaml key, for example
+// --kube.timeout. DefineFlags registers such flags for a config type.
+func Flags(fs *pflag.FlagSet) Source {
+	return flagSource{fs: fs}
+}
+
+func (s flagSource) String() string { return "flags" }
+
+func (s flagSource) apply(l *loader) error {
+	for _, f := range l.fields {
+		if f.key == "" {
+			continue
+		}
+		fl := s.fs.Lookup(f.key)
+		if fl == nil || !fl.Changed {
+			continue
+		}
+		if err := l.set(f, fl.Value.String(), Origin{Source: "flags", Key: "--" + f.key}); err != nil {
+			return fmt.Errorf("flag --%s -> %w", f.key, err)
+		}
+	}
+	return nil
+}
+
+// DefineFlags registers a flag for every field of T that has a yaml key.
+// Flags are parsed as strings and converted when the Flags source is loaded,
+// except bool fields which are registered as bool flags.
+func DefineFlags[T any](fs *pflag.FlagSet) {
+	var cfg T
+	for _, f := range collectFields(reflect.ValueOf(&cfg), "") {
+		if f.key == "" || fs.Lookup(f.key) != nil {
+			continue
+		}
+		if f.value.Kind() == reflect.Bool {
+			fs.Bool(f.key, false, f.desc)
+			continue
+		}
+		fs.String(f.key, "", f.desc)
+	}
+}
+
+func (s fileSource) watchDir() string { return filepath.Dir(s.path) }
+
+func (s dirSource) watchDir() string { return s.path }
@@ -0,0 +1,142 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sangrita-tech/platform-go-pkg/pkg/config"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/require"
+)
+
+type layeredCfg struct {
+	Port    int           `yaml:"port" env:"LAYERED_PORT" env-default:"80"`
+	Name    string        `yaml:"name" env:"LAYERED_NAME" env-default:"default"`
+	Debug   bool          `yaml:"debug" env:"LAYERED_DEBUG"`
+	Timeout time.Duration `yaml:"timeout" env:"LAYERED_TIMEOUT" env-default:"1s"`
+	DB      struct {
+		Host string `yaml:"host" env:"LAYERED_DB_HOST" env-default:"localhost"`
+	} `yaml:"db"`
+}
+
+func unsetLayeredEnv() {
+	for _, env := range []string{"LAYERED_PORT", "LAYERED_NAME", "LAYERED_DEBUG", "LAYERED_TIMEOUT", "LAYERED_DB_HOST"} {
+		os.Unsetenv(env)
+	}
+}
+
+func Test_LoadFrom_DefaultsOnly_ReturnsDefaults(t *testing.T) {
+	unsetLayeredEnv()
+
+	cfg, err := config.LoadFrom[layeredCfg]([]config.Source{config.Defaults()})
+
+	require.NoError(t, err)
+	require.Equal(t, 80, cfg.Port)
+	require.Equal(t, "default", cfg.Name)
+	require.Equal(t, time.Second, cfg.Timeout)
+	require.Equal(t, "localhost", cfg.DB.Host)
+}
+
+func Test_LoadFrom_BaseAndOverlayFiles_LaterFileWins(t *testing.T) {
+	unsetLayeredEnv()
+	dir := t.TempDir()
+	base := writeTempFile(t, dir, "base.yaml", "port: 1000\nname: base\ndb:\n  host: base-db\n")
+	overlay := writeTempFile(t, dir, "prod.json", `{"name": "prod"}`)
+
+	cfg, err := config.LoadFrom[layeredCfg]([]config.Source{
+		config.Defaults(),
+		config.File(base),
+		config.File(overlay),
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, 1000, cfg.Port)
+	require.Equal(t, "prod", cfg.Name)
+	require.Equal(t, "base-db", cfg.DB.Host)
+	require.Equal(t, time.Second, cfg.Timeout)
+}
+
+func Test_LoadFrom_Dir_AppliesFragmentsInLexicalOrder(t *testing.T) {
+	unsetLayeredEnv()
+	dir := t.TempDir()
+	writeTempFile(t, dir, "20-name.toml", "name = \"second\"\n")
+	writeTempFile(t, dir, "10-base.yaml", "port: 1000\nname: first\n")
+	writeTempFile(t, dir, "README.md", "not a config fragment")
+
+	cfg, err := config.LoadFrom[layeredCfg]([]config.Source{config.Dir(dir)})
+
+	require.NoError(t, err)
+	require.Equal(t, 1000, cfg.Port)
+	require.Equal(t, "second", cfg.Name)
+}
+
+func Test_LoadFrom_EnvAndFlags_FlagsWin(t *testing.T) {
+	unsetLayeredEnv()
+	t.Setenv("LAYERED_PORT", "2000")
+	t.Setenv("LAYERED_NAME", "from-env")
+	path := writeTempFile(t, t.TempDir(), "cfg.yaml", "port: 1000\nname: from-file\n")
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	config.DefineFlags[layeredCfg](fs)
+	require.NoError(t, fs.Parse([]string{"--name=from-flag", "--debug", "--db.host=flag-db"}))
+
+	cfg, err := config.LoadFrom[layeredCfg]([]config.Source{
+		config.Defaults(),
+		config.File(path),
+		config.Env(),
+		config.Flags(fs),
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, 2000, cfg.Port)
+	require.Equal(t, "from-flag", cfg.Name)
+	require.True(t, cfg.Debug)
+	require.Equal(t, "flag-db", cfg.DB.Host)
+}
+
+func Test_LoadFrom_OrderDefinesPrecedence(t *testing.T) {
+	unsetLayeredEnv()
+	t.Setenv("LAYERED_NAME", "from-env")
+	path := writeTempFile(t, t.TempDir(), "cfg.yaml", "name: from-file\n")
+
+	cfg, err := config.LoadFrom[layeredCfg]([]config.Source{config.Env(), config.File(path)})
+
+	require.NoError(t, err)
+	require.Equal(t, "from-file", cfg.Name)
+}
+
+func Test_LoadFrom_MissingFile_SkippedUnlessStrict(t *testing.T) {
+	unsetLayeredEnv()
+	missing := filepath.Join(t.TempDir(), "nope.yaml")
+	sources := []config.Source{config.Defaults(), config.File(missing)}
+
+	cfg, err := config.LoadFrom[layeredCfg](sources)
+	require.NoError(t, err)
+	require.Equal(t, 80, cfg.Port)
+
+	_, err = config.LoadFrom[layeredCfg](sources, config.WithStrictFiles())
+	require.ErrorContains(t, err, missing)
+}
+
+func Test_LoadFrom_MissingDir_SkippedUnlessStrict(t *testing.T) {
+	unsetLayeredEnv()
+	missing := filepath.Join(t.TempDir(), "conf.d")
+
+	_, err := config.LoadFrom[layeredCfg]([]config.Source{config.Dir(missing)})
+	require.NoError(t, err)
+
+	_, err = config.LoadFrom[layeredCfg]([]config.Source{config.Dir(missing)}, config.WithStrictFiles())
+	require.Error(t, err)
+}
+
+func Test_LoadFrom_InvalidEnvValue_ReturnsErrorAndZero(t *testing.T) {
+	unsetLayeredEnv()
+	t.Setenv("LAYERED_TIMEOUT", "soon")
+
+	cfg, err := config.LoadFrom[layeredCfg]([]config.Source{config.Defaults(), config.Env()})
+
+	require.ErrorContains(t, err, "LAYERED_TIMEOUT")
+	require.Equal(t, layeredCfg{}, cfg)
+}
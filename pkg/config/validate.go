@@ -0,0 +1,285 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const tagValidate = "validate"
+
+// Validator is implemented by config types that check themselves. LoadFrom
+// calls Validate on the loaded config and on the nested struct fields that
+// implement it, with either a value or a pointer receiver. Fields inside a
+// nested Validator are left to its Validate, which is expected to check
+// them, so their errors are not reported twice.
+type Validator interface {
+	Validate() error
+}
+
+// FieldError describes one invalid field. Field is the Go path of the field,
+// Key its yaml path and Env the first env var it is read from, if any.
+type FieldError struct {
+	Field string
+	Key   string
+	Env   string
+	Err   error
+}
+
+func (e FieldError) Error() string {
+	var b strings.Builder
+
+	switch {
+	case e.Key != "":
+		b.WriteString(e.Key)
+	case e.Field != "":
+		b.WriteString(e.Field)
+	default:
+		b.WriteString("config")
+	}
+	if e.Env != "" {
+		b.WriteString(" (" + e.Env + ")")
+	}
+	b.WriteString(": " + e.Err.Error())
+
+	return b.String()
+}
+
+func (e FieldError) Unwrap() error {
+	return e.Err
+}
+
+func (e FieldError) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("field", e.Field)
+	enc.AddString("key", e.Key)
+	if e.Env != "" {
+		enc.AddString("env", e.Env)
+	}
+	enc.AddString("error", e.Err.Error())
+	return nil
+}
+
+// ValidationError lists every invalid field found while loading a config.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for i, fe := range e.Fields {
+		msgs[i] = fe.Error()
+	}
+	return fmt.Sprintf("%d invalid field(s) -> %s", len(e.Fields), strings.Join(msgs, "; "))
+}
+
+func (e *ValidationError) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	return enc.AddArray("fields", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+		for _, fe := range e.Fields {
+			if err := arr.AppendObject(fe); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
+
+// validate runs the tag rules of every field and then the Validator
+// implementations, collecting all problems into a single ValidationError.
+func (l *loader) validate() error {
+	var errs []FieldError
+
+	for _, f := range l.fields {
+		if err := checkField(f); err != nil {
+			errs = append(errs, FieldError{Field: f.name, Key: f.key, Env: firstEnv(f), Err: err})
+		}
+	}
+
+	validateStructs(l.root.Elem(), "", "", true, &errs)
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return &ValidationError{Fields: errs}
+}
+
+func validateStructs(v reflect.Value, name, key string, root bool, errs *[]FieldError) {
+	vd, isValidator := v.Addr().Interface().(Validator)
+	if isValidator && !root {
+		if err := vd.Validate(); err != nil {
+			*errs = append(*errs, FieldError{Field: name, Key: key, Err: err})
+		}
+		return
+	}
+
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() || !isNested(sf.Type) {
+			continue
+		}
+
+		fKey, inline := yamlKey(sf)
+		if inline {
+			fKey = key
+		} else if fKey != "" {
+			fKey = joinPath(key, fKey)
+		}
+		validateStructs(v.Field(i), joinPath(name, sf.Name), fKey, false, errs)
+	}
+
+	if isValidator {
+		if err := vd.Validate(); err != nil {
+			*errs = append(*errs, FieldError{Field: name, Key: key, Err: err})
+		}
+	}
+}
+
+func firstEnv(f *field) string {
+	if len(f.envs) == 0 {
+		return ""
+	}
+	return f.envs[0]
+}
+
+func checkField(f *field) error {
+	if f.required && f.value.IsZero() {
+		return errors.New("is required but the value is not provided")
+	}
+
+	if f.rules == "" {
+		return nil
+	}
+
+	for _, rule := range strings.Split(f.rules, ",") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+
+		var err error
+		switch name {
+		case "":
+			continue
+		case "omitempty":
+			if f.value.IsZero() {
+				return nil
+			}
+		case "required":
+			if f.value.IsZero() {
+				err = errors.New("is required")
+			}
+		case "required_if":
+			err = checkRequiredIf(f, arg)
+		case "min":
+			err = checkBound(f.value, arg, true)
+		case "max":
+			err = checkBound(f.value, arg, false)
+		case "oneof":
+			err = checkOneOf(f.value, arg)
+		case "url":
+			err = checkURL(f.value)
+		default:
+			err = fmt.Errorf("unknown validate rule %q", name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func checkRequiredIf(f *field, arg string) error {
+	other, want, _ := strings.Cut(arg, " ")
+
+	ov := f.parent.FieldByName(other)
+	if !ov.IsValid() {
+		return fmt.Errorf("required_if refers to unknown field %q", other)
+	}
+
+	if !ov.CanInterface() {
+		return fmt.Errorf("required_if refers to unexported field %q", other)
+	}
+
+	if fmt.Sprint(ov.Interface()) == want && f.value.IsZero() {
+		return fmt.Errorf("is required when %s is %s", other, want)
+	}
+	return nil
+}
+
+// checkBound compares numbers and durations by value and strings, slices
+// and maps by length.
+func checkBound(v reflect.Value, arg string, isMin bool) error {
+	word := "at least"
+	if !isMin {
+		word = "at most"
+	}
+
+	if v.Type() == durationType {
+		limit, err := time.ParseDuration(arg)
+		if err != nil {
+			return fmt.Errorf("invalid duration bound %q", arg)
+		}
+		d := time.Duration(v.Int())
+		if (isMin && d < limit) || (!isMin && d > limit) {
+			return fmt.Errorf("must be %s %s, got %s", word, limit, d)
+		}
+		return nil
+	}
+
+	var (
+		got    float64
+		length bool
+	)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		got = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		got = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		got = v.Float()
+	case reflect.String, reflect.Slice, reflect.Map:
+		got, length = float64(v.Len()), true
+	default:
+		return fmt.Errorf("min/max not supported for %s", v.Type())
+	}
+
+	limit, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		return fmt.Errorf("invalid bound %q", arg)
+	}
+	if (isMin && got < limit) || (!isMin && got > limit) {
+		if length {
+			return fmt.Errorf("length must be %s %s, got %v", word, arg, got)
+		}
+		return fmt.Errorf("must be %s %s, got %v", word, arg, v.Interface())
+	}
+	return nil
+}
+
+func checkOneOf(v reflect.Value, arg string) error {
+	got := fmt.Sprint(v.Interface())
+	allowed := strings.Fields(arg)
+	for _, a := range allowed {
+		if got == a {
+			return nil
+		}
+	}
+	return fmt.Errorf("must be one of [%s], got %q", strings.Join(allowed, " "), got)
+}
+
+func checkURL(v reflect.Value) error {
+	if v.Kind() != reflect.String {
+		return fmt.Errorf("url not supported for %s", v.Type())
+	}
+
+	u, err := url.Parse(v.String())
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("must be an absolute url, got %q", v.String())
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package config_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/sangrita-tech/platform-go-pkg/pkg/config"
+	"github.com/sangrita-tech/platform-go-pkg/pkg/healthcheck"
+	"github.com/sangrita-tech/platform-go-pkg/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+type tlsCfg struct {
+	Enabled  bool   `yaml:"enabled"`
+	CertFile string `yaml:"certFile" env:"VAL_TLS_CERT" validate:"required_if=Enabled true"`
+}
+
+type validatedCfg struct {
+	Workers  int           `yaml:"workers" validate:"min=1,max=8"`
+	Mode     string        `yaml:"mode" env:"VAL_MODE" validate:"oneof=fast safe"`
+	Endpoint string        `yaml:"endpoint" validate:"omitempty,url"`
+	Interval time.Duration `yaml:"interval" validate:"min=1s,max=1m"`
+	Tags     []string      `yaml:"tags" validate:"max=2"`
+	TLS      tlsCfg        `yaml:"tls"`
+
+	Health healthcheck.Config `yaml:"health" env-prefix:"VAL_HEALTH_"`
+}
+
+func (c *validatedCfg) Validate() error {
+	if c.Mode == "fast" && c.Workers < 4 {
+		return errors.New("fast mode needs at least 4 workers")
+	}
+	return nil
+}
+
+func loadValidated(t *testing.T, content string) (validatedCfg, error) {
+	t.Helper()
+
+	os.Unsetenv("VAL_MODE")
+	os.Unsetenv("VAL_TLS_CERT")
+	path := writeTempFile(t, t.TempDir(), "cfg.yaml", content)
+
+	return config.LoadFrom[validatedCfg]([]config.Source{config.Defaults(), config.File(path), config.Env()})
+}
+
+func Test_LoadFrom_ValidCfg_ReturnsCfg(t *testing.T) {
+	cfg, err := loadValidated(t, "workers: 4\nmode: fast\ninterval: 10s\nendpoint: https://example.com\n")
+
+	require.NoError(t, err)
+	require.Equal(t, 4, cfg.Workers)
+	require.Equal(t, ":8080", cfg.Health.Addr)
+}
+
+func Test_LoadFrom_InvalidFields_ReportsEveryField(t *testing.T) {
+	_, err := loadValidated(t, `
+workers: 9
+mode: slow
+endpoint: not-a-url
+interval: 2m
+tags: [a, b, c]
+tls:
+  enabled: true
+health:
+  shutdown_timeout: -1s
+`)
+
+	var verr *config.ValidationError
+	require.ErrorAs(t, err, &verr)
+
+	byKey := map[string]config.FieldError{}
+	for _, fe := range verr.Fields {
+		byKey[fe.Key] = fe
+	}
+	require.Len(t, byKey, 7)
+	require.ErrorContains(t, byKey["workers"], "at most 8")
+	require.ErrorContains(t, byKey["mode"], "one of [fast safe]")
+	require.Equal(t, "VAL_MODE", byKey["mode"].Env)
+	require.ErrorContains(t, byKey["endpoint"], "absolute url")
+	require.ErrorContains(t, byKey["interval"], "at most 1m0s")
+	require.ErrorContains(t, byKey["tags"], "length must be at most 2")
+	require.ErrorContains(t, byKey["tls.certFile"], "required when Enabled is true")
+	require.Equal(t, "VAL_TLS_CERT", byKey["tls.certFile"].Env)
+	require.ErrorContains(t, byKey["health"], "shutdown timeout must be positive")
+}
+
+func Test_LoadFrom_RootValidatorFails_ReturnsError(t *testing.T) {
+	_, err := loadValidated(t, "workers: 2\nmode: fast\ninterval: 10s\n")
+
+	var verr *config.ValidationError
+	require.ErrorAs(t, err, &verr)
+	require.Len(t, verr.Fields, 1)
+	require.ErrorContains(t, verr.Fields[0], "fast mode needs at least 4 workers")
+}
+
+func Test_LoadFrom_MissingRequired_ReportedAsFieldError(t *testing.T) {
+	os.Unsetenv("PORT")
+	t.Setenv("NAME", "x")
+
+	_, err := config.Load[testCfg]("")
+
+	var verr *config.ValidationError
+	require.ErrorAs(t, err, &verr)
+	require.Equal(t, "PORT", verr.Fields[0].Env)
+	require.Equal(t, "port", verr.Fields[0].Key)
+}
+
+type nestedValidatorCfg struct {
+	Log logger.Config `yaml:"log"`
+}
+
+func Test_LoadFrom_NestedValidatorChildren_ReportedOnce(t *testing.T) {
+	path := writeTempFile(t, t.TempDir(), "cfg.yaml", "log:\n  level: info\n  format: json\n  sampling:\n    initial: -1\n")
+
+	_, err := config.LoadFrom[nestedValidatorCfg]([]config.Source{config.File(path)})
+
+	var verr *config.ValidationError
+	require.ErrorAs(t, err, &verr)
+	require.Len(t, verr.Fields, 1)
+	require.Equal(t, "log", verr.Fields[0].Key)
+	require.ErrorContains(t, verr.Fields[0], "sampling -> initial, thereafter and rate limit must be >= 0")
+}
+
+type unexportedRefCfg struct {
+	CertFile string `yaml:"certFile" validate:"required_if=enabled true"`
+	enabled  bool
+}
+
+func Test_LoadFrom_RequiredIfUnexportedField_ReturnsError(t *testing.T) {
+	_, err := config.LoadFrom[unexportedRefCfg]([]config.Source{config.Defaults()})
+
+	require.ErrorContains(t, err, `required_if refers to unexported field "enabled"`)
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ilyakaznacheev/cleanenv"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// parseValue parses a raw string the same way cleanenv does for env vars.
+func parseValue(v reflect.Value, raw, sep string, layout *string) error {
+	switch v.Type() {
+	case reflect.TypeOf(time.Time{}):
+		l := time.RFC3339
+		if layout != nil {
+			l = *layout
+		}
+		t, err := time.Parse(l, raw)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	case reflect.TypeOf(url.URL{}):
+		u, err := url.Parse(raw)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(*u))
+		return nil
+	case reflect.TypeOf(&time.Location{}):
+		loc, err := time.LoadLocation(raw)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(loc))
+		return nil
+	}
+
+	if v.CanAddr() {
+		switch p := v.Addr().Interface().(type) {
+		case encoding.TextUnmarshaler:
+			return p.UnmarshalText([]byte(raw))
+		case cleanenv.Setter:
+			return p.SetValue(raw)
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == durationType {
+			d, err := time.ParseDuration(raw)
+			if err != nil {
+				return err
+			}
+			v.SetInt(int64(d))
+			return nil
+		}
+		n, err := strconv.ParseInt(raw, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(raw, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			v.SetBytes([]byte(raw))
+			return nil
+		}
+		parts := strings.Split(raw, sep)
+		out := reflect.MakeSlice(v.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			if err := parseValue(out.Index(i), part, sep, layout); err != nil {
+				return err
+			}
+		}
+		v.Set(out)
+	case reflect.Map:
+		out := reflect.MakeMapWithSize(v.Type(), 0)
+		for _, pair := range strings.Split(raw, sep) {
+			k, val, ok := strings.Cut(pair, ":")
+			if !ok {
+				return fmt.Errorf("invalid map item %q", pair)
+			}
+			key := reflect.New(v.Type().Key()).Elem()
+			if err := parseValue(key, k, sep, layout); err != nil {
+				return err
+			}
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := parseValue(elem, val, sep, layout); err != nil {
+				return err
+			}
+			out.SetMapIndex(key, elem)
+		}
+		v.Set(out)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
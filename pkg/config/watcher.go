@@ -0,0 +1,265 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+const watchDebounce = 100 * time.Millisecond
+
+// watchedSource is implemented by sources that can notify about changes
+// themselves rather than through the file system.
+type watchedSource interface {
+	watch(ctx context.Context, notify func()) error
+}
+
+// pathSource is implemented by sources read from the local file system.
+type pathSource interface {
+	watchDir() string
+}
+
+// Watcher keeps a typed configuration up to date.
+//
+// Directories are watched rather than files, so Kubernetes ConfigMap and
+// Secret volume updates (an atomic swap of the "..data" symlink) are picked
+// up the same way as in-place writes.
+type Watcher[T any] struct {
+	sources  []Source
+	opts     []Option
+	validate func(*T) error
+
+	current  atomic.Pointer[T]
+	reloadMu sync.Mutex
+
+	mu      sync.Mutex
+	nextID  int
+	subs    map[int]func(old, new T)
+	onError func(err error)
+}
+
+// NewWatcher loads the configuration at path like Load does and returns a
+// watcher for it. The file must exist. validate is optional; a config it
+// rejects is never published.
+func NewWatcher[T any](path string, validate func(*T) error) (*Watcher[T], error) {
+	if path == "" {
+		return nil, errors.New("config -> watch path is empty")
+	}
+
+	return NewSourceWatcher[T]([]Source{Defaults(), File(path), Env()}, validate, WithStrictFiles())
+}
+
+// NewSourceWatcher loads the configuration from sources like LoadFrom does
+// and returns a watcher that reloads it when a File or Dir source, a file
+// backed Secret or a Kubernetes source changes.
+func NewSourceWatcher[T any](sources []Source, validate func(*T) error, opts ...Option) (*Watcher[T], error) {
+	w := &Watcher[T]{
+		sources:  sources,
+		opts:     opts,
+		validate: validate,
+		subs:     make(map[int]func(old, new T)),
+	}
+
+	cfg, err := w.load()
+	if err != nil {
+		return nil, fmt.Errorf("config -> failed to load initial config -> %w", err)
+	}
+	w.current.Store(cfg)
+
+	return w, nil
+}
+
+// Get returns the last successfully loaded configuration.
+func (w *Watcher[T]) Get() T {
+	return *w.current.Load()
+}
+
+// Subscribe registers fn to be called with the previous and the new config
+// after each successful reload. The returned func removes the subscription.
+func (w *Watcher[T]) Subscribe(fn func(old, new T)) func() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	id := w.nextID
+	w.nextID++
+	w.subs[id] = fn
+
+	return func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		delete(w.subs, id)
+	}
+}
+
+// OnError sets fn to be called when a reload fails. The previous config is
+// kept in that case.
+func (w *Watcher[T]) OnError(fn func(err error)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onError = fn
+}
+
+// Reload re-reads the sources and publishes the result if it differs from
+// the current config.
+func (w *Watcher[T]) Reload() error {
+	err := w.reload()
+	if err != nil {
+		w.mu.Lock()
+		onError := w.onError
+		w.mu.Unlock()
+
+		if onError != nil {
+			onError(err)
+		}
+	}
+	return err
+}
+
+// Run watches the sources until ctx is done.
+func (w *Watcher[T]) Run(ctx context.Context) error {
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("config -> failed to create fs watcher -> %w", err)
+	}
+	defer fw.Close()
+
+	watched := make(map[string]bool)
+	if err := w.watchDirs(fw, watched); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	notify := make(chan struct{}, 1)
+	errCh := make(chan error, len(w.sources))
+	for _, src := range w.sources {
+		ws, ok := src.(watchedSource)
+		if !ok {
+			continue
+		}
+		go func() {
+			err := ws.watch(ctx, func() {
+				select {
+				case notify <- struct{}{}:
+				default:
+				}
+			})
+			if err != nil {
+				errCh <- fmt.Errorf("config -> failed to watch %s -> %w", src, err)
+			}
+		}()
+	}
+
+	timer := time.NewTimer(0)
+	if !timer.Stop() {
+		<-timer.C
+	}
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-errCh:
+			return err
+		case err, ok := <-fw.Errors:
+			if !ok {
+				return nil
+			}
+			return fmt.Errorf("config -> fs watcher failed -> %w", err)
+		case _, ok := <-fw.Events:
+			if !ok {
+				return nil
+			}
+			timer.Reset(watchDebounce)
+		case <-notify:
+			timer.Reset(watchDebounce)
+		case <-timer.C:
+			_ = w.Reload()
+			if err := w.watchDirs(fw, watched); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (w *Watcher[T]) reload() error {
+	w.reloadMu.Lock()
+	defer w.reloadMu.Unlock()
+
+	cfg, err := w.load()
+	if err != nil {
+		return fmt.Errorf("config -> failed to reload -> %w", err)
+	}
+
+	old := w.current.Load()
+	if reflect.DeepEqual(old, cfg) {
+		return nil
+	}
+	w.current.Store(cfg)
+
+	w.mu.Lock()
+	subs := make([]func(old, new T), 0, len(w.subs))
+	for _, fn := range w.subs {
+		subs = append(subs, fn)
+	}
+	w.mu.Unlock()
+
+	for _, fn := range subs {
+		fn(*old, *cfg)
+	}
+
+	return nil
+}
+
+func (w *Watcher[T]) load() (*T, error) {
+	cfg, err := LoadFrom[T](w.sources, w.opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if w.validate != nil {
+		if err := w.validate(&cfg); err != nil {
+			return nil, fmt.Errorf("invalid config -> %w", err)
+		}
+	}
+
+	return &cfg, nil
+}
+
+// watchDirs adds the directories of file sources and of the secret files of
+// the current config that are not watched yet.
+func (w *Watcher[T]) watchDirs(fw *fsnotify.Watcher, watched map[string]bool) error {
+	var dirs []string
+	for _, src := range w.sources {
+		if ps, ok := src.(pathSource); ok {
+			dirs = append(dirs, ps.watchDir())
+		}
+	}
+	for _, path := range secretFiles(w.current.Load()) {
+		dirs = append(dirs, filepath.Dir(path))
+	}
+
+	for _, dir := range dirs {
+		if watched[dir] {
+			continue
+		}
+		if _, err := os.Stat(dir); errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err := fw.Add(dir); err != nil {
+			return fmt.Errorf("config -> failed to watch %q -> %w", dir, err)
+		}
+		watched[dir] = true
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package config_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sangrita-tech/platform-go-pkg/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func runWatcher[T any](t *testing.T, w *config.Watcher[T]) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- w.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		require.NoError(t, <-done)
+	})
+
+	// give fsnotify a moment to register the directory watch
+	time.Sleep(50 * time.Millisecond)
+}
+
+func Test_NewWatcher_MissingFile_ReturnsError(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "nope.yaml")
+
+	w, err := config.NewWatcher[testCfg](missing, nil)
+
+	require.Error(t, err)
+	require.Nil(t, w)
+}
+
+func Test_Watcher_FileChanged_PublishesNewCfg(t *testing.T) {
+	os.Unsetenv("PORT")
+	os.Unsetenv("NAME")
+	dir := t.TempDir()
+	path := writeTempFile(t, dir, "cfg.yaml", "port: 1000\nname: first\n")
+
+	w, err := config.NewWatcher[testCfg](path, nil)
+	require.NoError(t, err)
+	require.Equal(t, "first", w.Get().Name)
+
+	type change struct{ old, new testCfg }
+	changes := make(chan change, 10)
+	w.Subscribe(func(old, new testCfg) {
+		changes <- change{old, new}
+	})
+	runWatcher(t, w)
+
+	writeTempFile(t, dir, "cfg.yaml", "port: 2000\nname: second\n")
+
+	select {
+	case c := <-changes:
+		require.Equal(t, "first", c.old.Name)
+		require.Equal(t, "second", c.new.Name)
+		require.Equal(t, 2000, c.new.Port)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change published")
+	}
+	require.Equal(t, "second", w.Get().Name)
+}
+
+func Test_Watcher_InvalidCfg_KeepsLastGood(t *testing.T) {
+	os.Unsetenv("PORT")
+	os.Unsetenv("NAME")
+	dir := t.TempDir()
+	path := writeTempFile(t, dir, "cfg.yaml", "port: 1000\nname: good\n")
+
+	validate := func(c *testCfg) error {
+		if c.Name == "bad" {
+			return errors.New("name must not be bad")
+		}
+		return nil
+	}
+
+	w, err := config.NewWatcher[testCfg](path, validate)
+	require.NoError(t, err)
+
+	errs := make(chan error, 10)
+	w.OnError(func(err error) {
+		errs <- err
+	})
+	w.Subscribe(func(old, new testCfg) {
+		t.Errorf("unexpected publish of %+v", new)
+	})
+	runWatcher(t, w)
+
+	writeTempFile(t, dir, "cfg.yaml", "port: 2000\nname: bad\n")
+
+	select {
+	case err := <-errs:
+		require.ErrorContains(t, err, "name must not be bad")
+	case <-time.After(5 * time.Second):
+		t.Fatal("no error reported")
+	}
+	require.Equal(t, "good", w.Get().Name)
+}
+
+func Test_Watcher_ConfigMapSymlinkSwap_PublishesNewCfg(t *testing.T) {
+	os.Unsetenv("PORT")
+	os.Unsetenv("NAME")
+	dir := t.TempDir()
+
+	// mimic the layout kubelet uses for ConfigMap volumes
+	v1 := filepath.Join(dir, "..v1")
+	require.NoError(t, os.Mkdir(v1, 0o700))
+	writeTempFile(t, v1, "cfg.yaml", "port: 1000\nname: v1\n")
+	require.NoError(t, os.Symlink("..v1", filepath.Join(dir, "..data")))
+	require.NoError(t, os.Symlink(filepath.Join("..data", "cfg.yaml"), filepath.Join(dir, "cfg.yaml")))
+
+	w, err := config.NewWatcher[testCfg](filepath.Join(dir, "cfg.yaml"), nil)
+	require.NoError(t, err)
+	require.Equal(t, "v1", w.Get().Name)
+
+	published := make(chan testCfg, 10)
+	w.Subscribe(func(_, new testCfg) {
+		published <- new
+	})
+	runWatcher(t, w)
+
+	v2 := filepath.Join(dir, "..v2")
+	require.NoError(t, os.Mkdir(v2, 0o700))
+	writeTempFile(t, v2, "cfg.yaml", "port: 2000\nname: v2\n")
+	require.NoError(t, os.Symlink("..v2", filepath.Join(dir, "..data_tmp")))
+	require.NoError(t, os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")))
+	require.NoError(t, os.RemoveAll(v1))
+
+	select {
+	case c := <-published:
+		require.Equal(t, "v2", c.Name)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change published")
+	}
+}
@@ -0,0 +1,51 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sangrita-tech/platform-go-pkg/pkg/logger"
+	"go.uber.org/zap/zapcore"
+)
+
+// Config of the Kubernetes Events recorder. Entries at Level and above
+// with an involved object become events. Events about one object are
+// limited to a burst of Burst and then QPS per second; similar events are
+// aggregated once MaxEvents of them are seen within MaxInterval. Reasons and
+// messages are redacted by Redaction, since anyone who can read events in
+// the namespace sees them.
+type Config struct {
+	Component   string                 `yaml:"component" env:"EVENTS_COMPONENT" env-default:"app"`
+	Host        string                 `yaml:"host" env:"EVENTS_HOST"`
+	Level       string                 `yaml:"level" env:"EVENTS_LEVEL" env-default:"warn"`
+	Burst       int                    `yaml:"burst" env:"EVENTS_BURST" env-default:"25"`
+	QPS         float32                `yaml:"qps" env:"EVENTS_QPS" env-default:"0.0033"`
+	MaxEvents   int                    `yaml:"maxEvents" env:"EVENTS_MAX_EVENTS" env-default:"10"`
+	MaxInterval time.Duration          `yaml:"maxInterval" env:"EVENTS_MAX_INTERVAL" env-default:"10m"`
+	Redaction   logger.RedactionConfig `yaml:"redaction" env-prefix:"EVENTS_REDACT_"`
+}
+
+func (c Config) Validate() error {
+	if c.Component == "" {
+		return errors.New("component must be set")
+	}
+
+	if _, err := zapcore.ParseLevel(c.Level); err != nil {
+		return fmt.Errorf("unknown level %q", c.Level)
+	}
+
+	if c.Burst < 0 || c.QPS < 0 || c.MaxEvents < 0 {
+		return errors.New("burst, qps and max events must not be negative")
+	}
+
+	if c.MaxInterval < 0 {
+		return errors.New("max interval must not be negative")
+	}
+
+	if err := c.Redaction.Validate(); err != nil {
+		return fmt.Errorf("redaction -> %w", err)
+	}
+
+	return nil
+}
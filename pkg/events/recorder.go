@@ -0,0 +1,225 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sync/atomic"
+
+	"github.com/sangrita-tech/platform-go-pkg/pkg/leaderelection"
+	"github.com/sangrita-tech/platform-go-pkg/pkg/logger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/tools/reference"
+)
+
+const (
+	FieldInvolvedObject = "involvedObject"
+	FieldReason         = "reason"
+)
+
+const ReasonLeaderElection = "LeaderElection"
+
+// Recorder emits Kubernetes Events through a client-go event broadcaster,
+// which aggregates and rate limits them.
+type Recorder struct {
+	broadcaster record.EventBroadcaster
+	recorder    record.EventRecorder
+	level       zapcore.Level
+	redactor    *logger.Redactor
+}
+
+func New(cfg *Config, clientset kubernetes.Interface) (*Recorder, func(), error) {
+	if cfg == nil {
+		return nil, nil, errors.New("events -> config is nil")
+	}
+
+	if clientset == nil {
+		return nil, nil, errors.New("events -> clientset is nil")
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("events -> failed to validate config -> %w", err)
+	}
+
+	level, _ := zapcore.ParseLevel(cfg.Level)
+
+	host := cfg.Host
+	if host == "" {
+		host, _ = os.Hostname()
+	}
+
+	b := record.NewBroadcaster(record.WithCorrelatorOptions(record.CorrelatorOptions{
+		BurstSize:            cfg.Burst,
+		QPS:                  cfg.QPS,
+		MaxEvents:            cfg.MaxEvents,
+		MaxIntervalInSeconds: int(math.Ceil(cfg.MaxInterval.Seconds())),
+	}))
+	b.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: clientset.CoreV1().Events("")})
+
+	r := &Recorder{
+		broadcaster: b,
+		recorder:    b.NewRecorder(scheme.Scheme, corev1.EventSource{Component: cfg.Component, Host: host}),
+		level:       level,
+		redactor:    logger.NewRedactor(&cfg.Redaction),
+	}
+
+	return r, b.Shutdown, nil
+}
+
+// Event records an event about the object ref points to.
+func (r *Recorder) Event(ref *corev1.ObjectReference, eventType, reason, message string) {
+	r.recorder.Event(ref, eventType, reason, message)
+}
+
+// InvolvedObject marks a log entry as being about the object ref points to.
+func InvolvedObject(ref *corev1.ObjectReference) zap.Field {
+	if ref == nil {
+		return zap.Skip()
+	}
+	return zap.Object(FieldInvolvedObject, objectRef{ref})
+}
+
+// InvolvedObjectFor is InvolvedObject for an object of a type registered in
+// the client-go scheme.
+func InvolvedObjectFor(obj runtime.Object) zap.Field {
+	ref, err := reference.GetReference(scheme.Scheme, obj)
+	if err != nil {
+		return zap.Skip()
+	}
+	return InvolvedObject(ref)
+}
+
+// Reason sets the reason of the event recorded for a log entry, which is
+// otherwise the level of the entry.
+func Reason(reason string) zap.Field {
+	return zap.String(FieldReason, reason)
+}
+
+type objectRef struct {
+	ref *corev1.ObjectReference
+}
+
+func (o objectRef) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("kind", o.ref.Kind)
+	if o.ref.Namespace != "" {
+		enc.AddString("namespace", o.ref.Namespace)
+	}
+	enc.AddString("name", o.ref.Name)
+	return nil
+}
+
+// Attach returns l that also records an event for every entry at the
+// configured level or above that has an InvolvedObject field.
+func (r *Recorder) Attach(l *zap.Logger) *zap.Logger {
+	return l.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return logger.Tee(core, r.Core())
+	}))
+}
+
+// Core is the zapcore.Core behind Attach.
+func (r *Recorder) Core() zapcore.Core {
+	return &eventCore{LevelEnabler: r.level, rec: r.recorder, red: r.redactor}
+}
+
+type eventCore struct {
+	zapcore.LevelEnabler
+	rec    record.EventRecorder
+	red    *logger.Redactor
+	fields []zapcore.Field
+}
+
+func (c *eventCore) With(fields []zapcore.Field) zapcore.Core {
+	return &eventCore{
+		LevelEnabler: c.LevelEnabler,
+		rec:          c.rec,
+		red:          c.red,
+		fields:       append(c.fields[:len(c.fields):len(c.fields)], fields...),
+	}
+}
+
+func (c *eventCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *eventCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	var (
+		ref    *corev1.ObjectReference
+		reason = ent.Level.CapitalString()
+		msg    = ent.Message
+	)
+
+	for _, fs := range [][]zapcore.Field{c.fields, fields} {
+		for _, f := range fs {
+			switch {
+			case f.Key == FieldInvolvedObject && f.Type == zapcore.ObjectMarshalerType:
+				if o, ok := f.Interface.(objectRef); ok {
+					ref = o.ref
+				}
+			case f.Key == FieldReason && f.Type == zapcore.StringType:
+				reason = f.String
+			case f.Type == zapcore.ErrorType:
+				if err, ok := f.Interface.(error); ok {
+					msg += ": " + err.Error()
+				}
+			}
+		}
+	}
+
+	if ref == nil {
+		return nil
+	}
+
+	eventType := corev1.EventTypeNormal
+	if ent.Level >= zapcore.WarnLevel {
+		eventType = corev1.EventTypeWarning
+	}
+	c.rec.Event(ref, eventType, c.red.Redact(reason), c.red.Redact(msg))
+	return nil
+}
+
+func (c *eventCore) Sync() error { return nil }
+
+// LeaderCallbacks returns cb that also records an event on the lease of cfg
+// when this replica starts or stops leading. client-go calls
+// OnStoppedLeading on every exit from Run, so it is only recorded after
+// OnStartedLeading.
+func (r *Recorder) LeaderCallbacks(cfg *leaderelection.Config, cb leaderelection.Callbacks) leaderelection.Callbacks {
+	id := cfg.IdentityOrHostname()
+	lease := &corev1.ObjectReference{
+		APIVersion: "coordination.k8s.io/v1",
+		Kind:       "Lease",
+		Namespace:  cfg.LeaseNamespace,
+		Name:       cfg.LeaseName,
+	}
+
+	var leading atomic.Bool
+	out := cb
+	out.OnStartedLeading = func(ctx context.Context) {
+		leading.Store(true)
+		r.Event(lease, corev1.EventTypeNormal, ReasonLeaderElection, id+" became leader")
+		if cb.OnStartedLeading != nil {
+			cb.OnStartedLeading(ctx)
+		}
+	}
+	out.OnStoppedLeading = func() {
+		if leading.Swap(false) {
+			r.Event(lease, corev1.EventTypeNormal, ReasonLeaderElection, id+" stopped leading")
+		}
+		if cb.OnStoppedLeading != nil {
+			cb.OnStoppedLeading()
+		}
+	}
+	return out
+}
@@ -0,0 +1,193 @@
+package events_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sangrita-tech/platform-go-pkg/pkg/events"
+	"github.com/sangrita-tech/platform-go-pkg/pkg/leaderelection"
+	"github.com/sangrita-tech/platform-go-pkg/pkg/logger"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newConfig() *events.Config {
+	return &events.Config{
+		Component:   "controller",
+		Host:        "node-1",
+		Level:       "warn",
+		Burst:       25,
+		QPS:         1. / 300.,
+		MaxEvents:   10,
+		MaxInterval: 10 * time.Minute,
+	}
+}
+
+func listEvents(t *testing.T, cs *fake.Clientset, namespace string, n int) []corev1.Event {
+	t.Helper()
+
+	var list *corev1.EventList
+	require.Eventually(t, func() bool {
+		var err error
+		list, err = cs.CoreV1().Events(namespace).List(context.Background(), metav1.ListOptions{})
+		return err == nil && len(list.Items) >= n
+	}, 5*time.Second, 10*time.Millisecond)
+	return list.Items
+}
+
+func Test_Attach_WarnWithInvolvedObject_RecordsEvent(t *testing.T) {
+	cs := fake.NewClientset()
+	r, cleanup, err := events.New(newConfig(), cs)
+	require.NoError(t, err)
+	defer cleanup()
+
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "api-0", Namespace: "shop"}}
+	pod.Kind, pod.APIVersion = "Pod", "v1"
+
+	l := r.Attach(zap.NewNop())
+	l.Info("synced", events.InvolvedObjectFor(pod))
+	l.Warn("no ready replicas", zap.String("unrelated", "x"))
+	l.With(events.InvolvedObjectFor(pod)).Error("failed to scale", events.Reason("ScaleFailed"), zap.Error(errors.New("quota exceeded")))
+
+	items := listEvents(t, cs, "shop", 1)
+	require.Len(t, items, 1)
+	require.Equal(t, corev1.EventTypeWarning, items[0].Type)
+	require.Equal(t, "ScaleFailed", items[0].Reason)
+	require.Equal(t, "failed to scale: quota exceeded", items[0].Message)
+	require.Equal(t, "api-0", items[0].InvolvedObject.Name)
+	require.Equal(t, "controller", items[0].Source.Component)
+}
+
+func Test_Attach_SecretsInMessageAndError_AreRedacted(t *testing.T) {
+	cs := fake.NewClientset()
+	r, cleanup, err := events.New(newConfig(), cs)
+	require.NoError(t, err)
+	defer cleanup()
+
+	ref := &corev1.ObjectReference{Kind: "ConfigMap", APIVersion: "v1", Namespace: "shop", Name: "settings"}
+	r.Attach(zap.NewNop()).Error("call with Bearer abc.def failed",
+		events.InvolvedObject(ref),
+		events.Reason("Bearer xyz"),
+		zap.Error(errors.New("rejected token eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOiIxIn0.c2ln")),
+	)
+
+	items := listEvents(t, cs, "shop", 1)
+	require.Equal(t, "call with [REDACTED] failed: rejected token [REDACTED]", items[0].Message)
+	require.Equal(t, "[REDACTED]", items[0].Reason)
+}
+
+func Test_Attach_RepeatedEntries_AreAggregated(t *testing.T) {
+	cs := fake.NewClientset()
+	r, cleanup, err := events.New(newConfig(), cs)
+	require.NoError(t, err)
+	defer cleanup()
+
+	ref := &corev1.ObjectReference{Kind: "ConfigMap", APIVersion: "v1", Namespace: "shop", Name: "settings"}
+	l := r.Attach(zap.NewNop())
+	for range 3 {
+		l.Warn("invalid settings", events.InvolvedObject(ref))
+	}
+
+	require.Eventually(t, func() bool {
+		list, err := cs.CoreV1().Events("shop").List(context.Background(), metav1.ListOptions{})
+		return err == nil && len(list.Items) == 1 && list.Items[0].Count == 3
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func Test_Attach_LoggerFromNew_KeepsTraceKeysAndLevels(t *testing.T) {
+	r, cleanup, err := events.New(newConfig(), fake.NewClientset())
+	require.NoError(t, err)
+	defer cleanup()
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	l, cleanupLogger, err := logger.New(&logger.Config{
+		Level:   "info",
+		Format:  "json",
+		Outputs: []logger.OutputConfig{{Type: logger.OutputFile, Path: path}},
+		Trace:   logger.TraceConfig{TraceIDKey: "trace.id", SpanIDKey: "span.id"},
+	})
+	require.NoError(t, err)
+
+	tp := sdktrace.NewTracerProvider()
+	defer func() { _ = tp.Shutdown(context.Background()) }()
+	ctx, span := tp.Tracer("test").Start(context.Background(), "op")
+	defer span.End()
+
+	attached := r.Attach(l)
+	logger.FromContext(logger.WithContext(ctx, attached)).Info("traced")
+	require.NotNil(t, logger.LevelsOf(attached))
+	cleanupLogger()
+
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(raw), `"trace.id"`)
+	require.NotContains(t, string(raw), `"trace_id"`)
+}
+
+func Test_LeaderCallbacks_RecordsTransitionsOnLease(t *testing.T) {
+	cs := fake.NewClientset()
+	r, cleanup, err := events.New(newConfig(), cs)
+	require.NoError(t, err)
+	defer cleanup()
+
+	started := false
+	cfg := &leaderelection.Config{LeaseName: "app-leader", LeaseNamespace: "ops", Identity: "pod-a"}
+	cb := r.LeaderCallbacks(cfg, leaderelection.Callbacks{
+		OnStartedLeading: func(context.Context) { started = true },
+	})
+
+	cb.OnStartedLeading(context.Background())
+	cb.OnStoppedLeading()
+	require.True(t, started)
+
+	items := listEvents(t, cs, "ops", 2)
+	msgs := []string{items[0].Message, items[1].Message}
+	require.ElementsMatch(t, []string{"pod-a became leader", "pod-a stopped leading"}, msgs)
+	require.Equal(t, "Lease", items[0].InvolvedObject.Kind)
+	require.Equal(t, events.ReasonLeaderElection, items[0].Reason)
+}
+
+func Test_LeaderCallbacks_NeverLed_DoesNotRecordStop(t *testing.T) {
+	cs := fake.NewClientset()
+	r, cleanup, err := events.New(newConfig(), cs)
+	require.NoError(t, err)
+	defer cleanup()
+
+	stopped := false
+	cfg := &leaderelection.Config{LeaseName: "app-leader", LeaseNamespace: "ops", Identity: "pod-b"}
+	cb := r.LeaderCallbacks(cfg, leaderelection.Callbacks{
+		OnStoppedLeading: func() { stopped = true },
+	})
+	cb.OnStoppedLeading()
+	require.True(t, stopped)
+
+	// Events are sent in order, so once the marker arrives a stop event
+	// would have too.
+	marker := &corev1.ObjectReference{Kind: "ConfigMap", APIVersion: "v1", Namespace: "ops", Name: "marker"}
+	r.Event(marker, corev1.EventTypeNormal, "Marker", "marker")
+
+	items := listEvents(t, cs, "ops", 1)
+	require.Len(t, items, 1)
+	require.Equal(t, "marker", items[0].Message)
+}
+
+func Test_New_InvalidConfig_ReturnsError(t *testing.T) {
+	_, _, err := events.New(nil, fake.NewClientset())
+	require.Error(t, err)
+
+	_, _, err = events.New(newConfig(), nil)
+	require.ErrorContains(t, err, "events -> clientset is nil")
+
+	cfg := newConfig()
+	cfg.Level = "loud"
+	_, _, err = events.New(cfg, fake.NewClientset())
+	require.ErrorContains(t, err, "events -> failed to validate config")
+}
@@ -26,7 +26,7 @@ func New(cfg *Config) (*Healthcheck, error) {
 		return nil, errors.New("healthcheck -> config is nil")
 	}
 
-	if err := cfg.validate(); err != nil {
+	if err := cfg.Validate(); err != nil {
 		return nil, fmt.Errorf("healthcheck -> failed to validate config -> %w", err)
 	}
 
@@ -88,6 +88,27 @@ func (h *Healthcheck) Register(p *Probe) error {
 	return nil
 }
 
+func (h *Healthcheck) Handle(route string, handler http.Handler) error {
+	if handler == nil {
+		return errors.New("healthcheck -> handler is nil")
+	}
+	if route == "" || route[0] != '/' {
+		return fmt.Errorf("healthcheck -> handler route must start with '/' -> %q", route)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, exists := h.handlers[route]; exists {
+		return fmt.Errorf("healthcheck -> route already registered -> %s", route)
+	}
+
+	h.handlers[route] = handler
+	h.mux.Handle(route, handler)
+
+	return nil
+}
+
 func (h *Healthcheck) Run(ctx context.Context) error {
 	errCh := make(chan error, 1)
 
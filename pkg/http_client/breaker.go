@@ -0,0 +1,299 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type State string
+
+const (
+	StateClosed   State = "closed"
+	StateOpen     State = "open"
+	StateHalfOpen State = "half-open"
+)
+
+const breakerBuckets = 10
+
+var ErrCircuitOpen = errors.New("circuit open")
+
+// CircuitOpenError is returned for requests to a host whose breaker is
+// open. It matches ErrCircuitOpen.
+type CircuitOpenError struct {
+	Host  string
+	Until time.Time
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("httpclient -> circuit open for %s until %s", e.Host, e.Until.UTC().Format(time.RFC3339))
+}
+
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+var (
+	breakerState = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "httpclient_circuit_breaker_state",
+		Help: "State of the circuit breaker of a host: 0 closed, 1 half-open, 2 open.",
+	}, []string{"client", "host"})
+	breakerRejected = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "httpclient_circuit_breaker_rejected_total",
+		Help: "Requests rejected by an open circuit breaker.",
+	}, []string{"client", "host"})
+)
+
+func stateValue(s State) float64 {
+	switch s {
+	case StateHalfOpen:
+		return 1
+	case StateOpen:
+		return 2
+	}
+	return 0
+}
+
+// Breakers are the circuit breakers of a client, one per host.
+type Breakers struct {
+	name string
+	cfg  *BreakerConfig
+	next http.RoundTripper
+
+	mu    sync.Mutex
+	hosts map[string]*breaker
+}
+
+func newBreakers(name string, cfg *BreakerConfig, next http.RoundTripper) *Breakers {
+	return &Breakers{name: name, cfg: cfg, next: next, hosts: make(map[string]*breaker)}
+}
+
+// BreakersOf returns the circuit breakers of c, or nil if c was not built by
+// New with the breaker enabled.
+func BreakersOf(c *retryablehttp.Client) *Breakers {
+	rt := c.HTTPClient.Transport
+	for rt != nil {
+		if b, ok := rt.(*Breakers); ok {
+			return b
+		}
+		u, ok := rt.(interface{ unwrap() http.RoundTripper })
+		if !ok {
+			return nil
+		}
+		rt = u.unwrap()
+	}
+	return nil
+}
+
+func (b *Breakers) unwrap() http.RoundTripper { return b.next }
+
+// States returns the state of the breaker of every host requested so far.
+func (b *Breakers) States() map[string]State {
+	b.mu.Lock()
+	hosts := make([]*breaker, 0, len(b.hosts))
+	for _, br := range b.hosts {
+		hosts = append(hosts, br)
+	}
+	b.mu.Unlock()
+
+	out := make(map[string]State, len(hosts))
+	for _, br := range hosts {
+		out[br.host] = br.current(time.Now())
+	}
+	return out
+}
+
+// Check returns an error naming the hosts whose breaker is open, for use as
+// a health check.
+func (b *Breakers) Check(context.Context) error {
+	var open []string
+	for host, s := range b.States() {
+		if s == StateOpen {
+			open = append(open, host)
+		}
+	}
+	if len(open) == 0 {
+		return nil
+	}
+	sort.Strings(open)
+	return fmt.Errorf("httpclient -> circuit open for %s", strings.Join(open, ", "))
+}
+
+func (b *Breakers) RoundTrip(req *http.Request) (*http.Response, error) {
+	br := b.breaker(req.URL.Host)
+
+	if err := br.allow(time.Now()); err != nil {
+		breakerRejected.WithLabelValues(b.name, br.host).Inc()
+		return nil, err
+	}
+
+	resp, err := b.next.RoundTrip(req)
+
+	switch {
+	case err != nil && (req.Context().Err() != nil || errors.Is(err, ErrWaitExceedsDeadline)):
+		// Canceled by the caller or held back by the client's own rate
+		// limit, says nothing about the host.
+		br.release()
+	case err != nil || resp.StatusCode >= http.StatusInternalServerError:
+		br.record(time.Now(), true)
+	default:
+		br.record(time.Now(), false)
+	}
+
+	return resp, err
+}
+
+func (b *Breakers) breaker(host string) *breaker {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	br, ok := b.hosts[host]
+	if !ok {
+		br = &breaker{host: host, cfg: b.cfg, state: StateClosed, stateGauge: breakerState.WithLabelValues(b.name, host)}
+		b.hosts[host] = br
+		br.stateGauge.Set(stateValue(StateClosed))
+	}
+	return br
+}
+
+type bucket struct {
+	start    time.Time
+	total    int
+	failures int
+}
+
+type breaker struct {
+	host       string
+	cfg        *BreakerConfig
+	stateGauge prometheus.Gauge
+
+	mu        sync.Mutex
+	state     State
+	openedAt  time.Time
+	buckets   [breakerBuckets]bucket
+	probes    int
+	successes int
+}
+
+func (br *breaker) halfOpenRequests() int {
+	return max(br.cfg.HalfOpenRequests, 1)
+}
+
+// current returns the state at now, moving an open breaker whose cooldown
+// passed to half-open.
+func (br *breaker) current(now time.Time) State {
+	br.mu.Lock()
+	defer br.mu.Unlock()
+
+	br.expire(now)
+	return br.state
+}
+
+func (br *breaker) expire(now time.Time) {
+	if br.state == StateOpen && now.Sub(br.openedAt) >= br.cfg.Cooldown {
+		br.probes, br.successes = 0, 0
+		br.transition(StateHalfOpen)
+	}
+}
+
+func (br *breaker) allow(now time.Time) error {
+	br.mu.Lock()
+	defer br.mu.Unlock()
+
+	br.expire(now)
+
+	switch br.state {
+	case StateOpen:
+		return &CircuitOpenError{Host: br.host, Until: br.openedAt.Add(br.cfg.Cooldown)}
+	case StateHalfOpen:
+		if br.probes >= br.halfOpenRequests() {
+			return &CircuitOpenError{Host: br.host, Until: now}
+		}
+		br.probes++
+	}
+	return nil
+}
+
+// release gives back a half-open probe whose outcome is unknown.
+func (br *breaker) release() {
+	br.mu.Lock()
+	defer br.mu.Unlock()
+
+	if br.state == StateHalfOpen && br.probes > 0 {
+		br.probes--
+	}
+}
+
+func (br *breaker) record(now time.Time, failed bool) {
+	br.mu.Lock()
+	defer br.mu.Unlock()
+
+	switch br.state {
+	case StateHalfOpen:
+		if failed {
+			br.open(now)
+			return
+		}
+		br.successes++
+		if br.successes >= br.halfOpenRequests() {
+			br.buckets = [breakerBuckets]bucket{}
+			br.transition(StateClosed)
+		}
+	case StateClosed:
+		b := br.bucket(now)
+		b.total++
+		if failed {
+			b.failures++
+		}
+
+		total, failures := br.counts(now)
+		if total >= max(br.cfg.MinRequests, 1) && float64(failures)/float64(total) >= br.cfg.FailureRate {
+			br.open(now)
+		}
+	}
+}
+
+func (br *breaker) open(now time.Time) {
+	br.openedAt = now
+	br.transition(StateOpen)
+}
+
+func (br *breaker) transition(to State) {
+	br.state = to
+	br.stateGauge.Set(stateValue(to))
+}
+
+func (br *breaker) bucketSize() time.Duration {
+	return max(br.cfg.Window/breakerBuckets, time.Nanosecond)
+}
+
+// bucket returns the bucket now falls into, cleared if it still holds an
+// older period.
+func (br *breaker) bucket(now time.Time) *bucket {
+	size := br.bucketSize()
+	start := now.Truncate(size)
+	b := &br.buckets[(start.UnixNano()/int64(size))%breakerBuckets]
+	if !b.start.Equal(start) {
+		*b = bucket{start: start}
+	}
+	return b
+}
+
+func (br *breaker) counts(now time.Time) (total, failures int) {
+	from := now.Add(-br.cfg.Window)
+	for _, b := range br.buckets {
+		if b.start.After(from) {
+			total += b.total
+			failures += b.failures
+		}
+	}
+	return total, failures
+}
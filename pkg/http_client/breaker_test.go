@@ -0,0 +1,174 @@
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/prometheus/client_golang/prometheus"
+	httpclient "github.com/sangrita-tech/platform-go-pkg/pkg/http_client"
+	"github.com/stretchr/testify/require"
+)
+
+func newBreakerConfig(retries int) *httpclient.Config {
+	return &httpclient.Config{
+		Timeout:      time.Second,
+		RetriesMax:   retries,
+		RetriesDelay: time.Millisecond,
+		Breaker: httpclient.BreakerConfig{
+			Enabled:          true,
+			Window:           time.Minute,
+			MinRequests:      4,
+			FailureRate:      0.5,
+			Cooldown:         50 * time.Millisecond,
+			HalfOpenRequests: 1,
+		},
+	}
+}
+
+func newBreakerClient(t *testing.T, retries int) *retryablehttp.Client {
+	t.Helper()
+
+	c, err := httpclient.New(newBreakerConfig(retries))
+	require.NoError(t, err)
+	return c
+}
+
+func Test_Breaker_FailingHost_OpensAndRecovers(t *testing.T) {
+	var calls atomic.Int32
+	var healthy atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+	host := mustHost(t, srv.URL)
+
+	c := newBreakerClient(t, 0)
+	breakers := httpclient.BreakersOf(c)
+	require.NotNil(t, breakers)
+
+	for range 4 {
+		resp, err := c.Get(srv.URL)
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+	}
+	require.Equal(t, httpclient.StateOpen, breakers.States()[host])
+	require.ErrorContains(t, breakers.Check(context.Background()), "circuit open for "+host)
+
+	_, err := c.Get(srv.URL)
+	var openErr *httpclient.CircuitOpenError
+	require.ErrorAs(t, err, &openErr)
+	require.Equal(t, host, openErr.Host)
+	require.ErrorIs(t, err, httpclient.ErrCircuitOpen)
+	require.Equal(t, int32(4), calls.Load())
+
+	time.Sleep(60 * time.Millisecond)
+	require.Equal(t, httpclient.StateHalfOpen, breakers.States()[host])
+
+	healthy.Store(true)
+	resp, err := c.Get(srv.URL)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	require.Equal(t, httpclient.StateClosed, breakers.States()[host])
+	require.NoError(t, breakers.Check(context.Background()))
+}
+
+func Test_Breaker_HalfOpenFailure_ReopensWithoutRetrying(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	c := newBreakerClient(t, 10)
+
+	_, err := c.Get(srv.URL)
+	require.ErrorIs(t, err, httpclient.ErrCircuitOpen)
+	require.Equal(t, int32(4), calls.Load())
+
+	time.Sleep(60 * time.Millisecond)
+	_, err = c.Get(srv.URL)
+	require.ErrorIs(t, err, httpclient.ErrCircuitOpen)
+	require.Equal(t, int32(5), calls.Load())
+	require.Equal(t, httpclient.StateOpen, httpclient.BreakersOf(c).States()[mustHost(t, srv.URL)])
+}
+
+func Test_Breaker_StateMetric_IsLabelledByClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+	host := mustHost(t, srv.URL)
+
+	failing := newBreakerConfig(0)
+	failing.Name = "failing"
+	fc, err := httpclient.New(failing)
+	require.NoError(t, err)
+
+	idle := newBreakerConfig(0)
+	idle.Name = "idle"
+	ic, err := httpclient.New(idle)
+	require.NoError(t, err)
+
+	if resp, err := ic.Get(srv.URL); err == nil {
+		_ = resp.Body.Close()
+	}
+	for range 4 {
+		if resp, err := fc.Get(srv.URL); err == nil {
+			_ = resp.Body.Close()
+		}
+	}
+
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(httpclient.MetricsCollectors()...)
+	families, err := reg.Gather()
+	require.NoError(t, err)
+
+	states := map[string]float64{}
+	for _, f := range families {
+		if f.GetName() != "httpclient_circuit_breaker_state" {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			labels := map[string]string{}
+			for _, lp := range m.GetLabel() {
+				labels[lp.GetName()] = lp.GetValue()
+			}
+			if labels["host"] == host {
+				states[labels["client"]] = m.GetGauge().GetValue()
+			}
+		}
+	}
+	require.Equal(t, map[string]float64{"failing": 2, "idle": 0}, states)
+}
+
+func Test_BreakersOf_Disabled_ReturnsNil(t *testing.T) {
+	c, err := httpclient.New(&httpclient.Config{Timeout: time.Second})
+	require.NoError(t, err)
+	require.Nil(t, httpclient.BreakersOf(c))
+}
+
+func Test_New_InvalidBreakerConfig_ReturnsError(t *testing.T) {
+	_, err := httpclient.New(&httpclient.Config{
+		Timeout: time.Second,
+		Breaker: httpclient.BreakerConfig{Enabled: true, Window: time.Second, Cooldown: time.Second, FailureRate: 2},
+	})
+	require.ErrorContains(t, err, "breaker -> failure rate must be in (0, 1]")
+}
+
+func mustHost(t *testing.T, raw string) string {
+	t.Helper()
+	u, err := url.Parse(raw)
+	require.NoError(t, err)
+	return u.Host
+}
@@ -3,10 +3,9 @@ package httpclient
 import (
 	"errors"
 	"fmt"
-	"net/http"
-	"time"
 
 	"github.com/hashicorp/go-retryablehttp"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 func New(cfg *Config) (*retryablehttp.Client, error) {
@@ -14,7 +13,7 @@ func New(cfg *Config) (*retryablehttp.Client, error) {
 		return nil, errors.New("httpclient -> config is nil")
 	}
 
-	if err := cfg.validate(); err != nil {
+	if err := cfg.Validate(); err != nil {
 		return nil, fmt.Errorf("httpclient -> failed to validate config -> %w", err)
 	}
 
@@ -23,13 +22,27 @@ func New(cfg *Config) (*retryablehttp.Client, error) {
 	client.HTTPClient.Timeout = cfg.Timeout
 	client.RetryMax = cfg.RetriesMax
 
+	// The breaker goes outside the limiter so that requests it rejects do
+	// not take up the rate limit.
+	if cfg.Limits.enabled() {
+		client.HTTPClient.Transport = newLimiter(cfg.Name, &cfg.Limits, client.HTTPClient.Transport)
+	}
+	if cfg.Breaker.Enabled {
+		client.HTTPClient.Transport = newBreakers(cfg.Name, &cfg.Breaker, client.HTTPClient.Transport)
+	}
+
 	if cfg.RetriesMax > 0 {
 		client.RetryWaitMin = cfg.RetriesDelay
-		client.RetryWaitMax = cfg.RetriesDelay
-		client.Backoff = func(min, max time.Duration, attemptNum int, resp *http.Response) time.Duration {
-			return cfg.RetriesDelay
-		}
+		client.RetryWaitMax = max(cfg.RetriesMaxDelay, cfg.RetriesDelay)
+		client.Backoff = newBackoff(cfg)
+		client.CheckRetry = newCheckRetry(cfg)
 	}
 
 	return client, nil
 }
+
+// MetricsCollectors returns the metrics of the client, to be registered
+// with the application's prometheus registry.
+func MetricsCollectors() []prometheus.Collector {
+	return []prometheus.Collector{breakerState, breakerRejected, limiterWait}
+}
@@ -0,0 +1,165 @@
+package httpclient_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	httpclient "github.com/sangrita-tech/platform-go-pkg/pkg/http_client"
+	"github.com/stretchr/testify/require"
+)
+
+func newClientConfig() *httpclient.Config {
+	return &httpclient.Config{Timeout: time.Second, RetriesMax: 3, RetriesDelay: 100 * time.Millisecond}
+}
+
+func Test_Backoff_Policies_ComputeDelays(t *testing.T) {
+	for _, tc := range []struct {
+		backoff string
+		want    []time.Duration
+	}{
+		{httpclient.BackoffConstant, []time.Duration{100, 100, 100, 100}},
+		{httpclient.BackoffLinear, []time.Duration{100, 200, 300, 400}},
+		{httpclient.BackoffExponential, []time.Duration{100, 200, 400, 500}},
+	} {
+		cfg := newClientConfig()
+		cfg.RetriesBackoff = tc.backoff
+		cfg.RetriesMaxDelay = 500 * time.Millisecond
+
+		c, err := httpclient.New(cfg)
+		require.NoError(t, err)
+
+		for i, want := range tc.want {
+			require.Equal(t, want*time.Millisecond, c.Backoff(c.RetryWaitMin, c.RetryWaitMax, i, nil), "%s attempt %d", tc.backoff, i)
+		}
+	}
+}
+
+func Test_Backoff_Jitter_StaysWithinBounds(t *testing.T) {
+	for _, tc := range []struct {
+		jitter string
+		min    time.Duration
+	}{
+		{httpclient.JitterFull, 0},
+		{httpclient.JitterEqual, 200 * time.Millisecond},
+	} {
+		cfg := newClientConfig()
+		cfg.RetriesBackoff = httpclient.BackoffExponential
+		cfg.RetriesJitter = tc.jitter
+
+		c, err := httpclient.New(cfg)
+		require.NoError(t, err)
+
+		for range 100 {
+			d := c.Backoff(c.RetryWaitMin, c.RetryWaitMax, 2, nil)
+			require.GreaterOrEqual(t, d, tc.min)
+			require.LessOrEqual(t, d, 400*time.Millisecond)
+		}
+	}
+}
+
+func Test_Backoff_RetryAfter_IsHonored(t *testing.T) {
+	cfg := newClientConfig()
+	cfg.RetriesMaxDelay = 10 * time.Second
+	c, err := httpclient.New(cfg)
+	require.NoError(t, err)
+
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": {"3"}}}
+	require.Equal(t, 3*time.Second, c.Backoff(c.RetryWaitMin, c.RetryWaitMax, 0, resp))
+
+	resp.Header.Set("Retry-After", "60")
+	require.Equal(t, 10*time.Second, c.Backoff(c.RetryWaitMin, c.RetryWaitMax, 0, resp))
+
+	resp.StatusCode = http.StatusBadGateway
+	require.Equal(t, 100*time.Millisecond, c.Backoff(c.RetryWaitMin, c.RetryWaitMax, 0, resp))
+
+	cfg.IgnoreRetryAfter = true
+	c, err = httpclient.New(cfg)
+	require.NoError(t, err)
+	resp.StatusCode = http.StatusServiceUnavailable
+	require.Equal(t, 100*time.Millisecond, c.Backoff(c.RetryWaitMin, c.RetryWaitMax, 0, resp))
+}
+
+func Test_CheckRetry_DistinguishesMethodsAndStatuses(t *testing.T) {
+	c, err := httpclient.New(newClientConfig())
+	require.NoError(t, err)
+
+	respond := func(method string, status int) *http.Response {
+		return &http.Response{StatusCode: status, Request: &http.Request{Method: method}}
+	}
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		method string
+		status int
+		retry  bool
+	}{
+		{http.MethodGet, http.StatusBadGateway, true},
+		{http.MethodPut, http.StatusInternalServerError, true},
+		{http.MethodGet, http.StatusNotImplemented, false},
+		{http.MethodGet, http.StatusNotFound, false},
+		{http.MethodPost, http.StatusBadGateway, false},
+		{http.MethodPost, http.StatusTooManyRequests, true},
+		{http.MethodPatch, http.StatusServiceUnavailable, true},
+	} {
+		retry, err := c.CheckRetry(ctx, respond(tc.method, tc.status), nil)
+		require.NoError(t, err)
+		require.Equal(t, tc.retry, retry, "%s %d", tc.method, tc.status)
+	}
+
+	reset := &url.Error{Op: "Post", URL: "http://api", Err: errors.New("connection reset by peer")}
+	retry, _ := c.CheckRetry(ctx, nil, reset)
+	require.False(t, retry)
+
+	refused := &url.Error{Op: "Post", URL: "http://api", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}
+	retry, _ = c.CheckRetry(ctx, nil, refused)
+	require.True(t, retry)
+
+	reset.Op = "Get"
+	retry, _ = c.CheckRetry(ctx, nil, reset)
+	require.True(t, retry)
+}
+
+func Test_Do_PostOnServerError_IsNotRetried(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	cfg := newClientConfig()
+	cfg.RetriesDelay = time.Millisecond
+	c, err := httpclient.New(cfg)
+	require.NoError(t, err)
+
+	resp, err := c.Post(srv.URL, "application/json", []byte(`{}`))
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	require.Equal(t, int32(1), calls.Load())
+
+	_, err = c.Get(srv.URL)
+	require.Error(t, err)
+	require.Equal(t, int32(5), calls.Load())
+}
+
+func Test_New_InvalidConfig_ReturnsError(t *testing.T) {
+	_, err := httpclient.New(nil)
+	require.Error(t, err)
+
+	cfg := newClientConfig()
+	cfg.RetriesBackoff = "fibonacci"
+	_, err = httpclient.New(cfg)
+	require.ErrorContains(t, err, `unknown retries backoff "fibonacci"`)
+
+	cfg = newClientConfig()
+	cfg.RetryStatuses = []int{42}
+	_, err = httpclient.New(cfg)
+	require.ErrorContains(t, err, "retry status 42")
+}
@@ -2,16 +2,53 @@ package httpclient
 
 import (
 	"errors"
+	"fmt"
 	"time"
 )
 
+const (
+	BackoffConstant    = "constant"
+	BackoffLinear      = "linear"
+	BackoffExponential = "exponential"
+)
+
+const (
+	JitterNone  = "none"
+	JitterFull  = "full"
+	JitterEqual = "equal"
+)
+
+// Config of the client. Retries wait RetriesDelay, growing with
+// RetriesBackoff (constant by default, linear or exponential) up to
+// RetriesMaxDelay, randomized by RetriesJitter (none, full or equal). A
+// Retry-After header of a 429 or 503 response is waited for instead, unless
+// IgnoreRetryAfter is set.
+//
+// RetryStatuses are the response statuses retried, 408, 429, 500, 502, 503
+// and 504 by default. Requests with non-idempotent methods like POST are
+// only retried when they were not sent or were answered with 429 or 503,
+// unless RetryNonIdempotent is set.
+//
+// Name labels the metrics of the client, so that clients calling the same
+// host can be told apart.
 type Config struct {
+	Name         string
 	Timeout      time.Duration
 	RetriesMax   int
 	RetriesDelay time.Duration
+
+	RetriesBackoff     string
+	RetriesJitter      string
+	RetriesMaxDelay    time.Duration
+	IgnoreRetryAfter   bool
+	RetryStatuses      []int
+	RetryNonIdempotent bool
+
+	Breaker BreakerConfig
+	Limits  LimitsConfig
 }
 
-func (c *Config) validate() error {
+func (c *Config) Validate() error {
 	if c.Timeout <= 0 {
 		return errors.New("timeout must be positive")
 	}
@@ -28,5 +65,107 @@ func (c *Config) validate() error {
 		return errors.New("retries delay must be positive when retries are enabled")
 	}
 
+	switch c.RetriesBackoff {
+	case "", BackoffConstant, BackoffLinear, BackoffExponential:
+	default:
+		return fmt.Errorf("unknown retries backoff %q", c.RetriesBackoff)
+	}
+
+	switch c.RetriesJitter {
+	case "", JitterNone, JitterFull, JitterEqual:
+	default:
+		return fmt.Errorf("unknown retries jitter %q", c.RetriesJitter)
+	}
+
+	if c.RetriesMaxDelay < 0 {
+		return errors.New("retries max delay cannot be negative")
+	}
+
+	for _, s := range c.RetryStatuses {
+		if s < 100 || s > 599 {
+			return fmt.Errorf("retry status %d is not a valid status code", s)
+		}
+	}
+
+	if err := c.Breaker.Validate(); err != nil {
+		return fmt.Errorf("breaker -> %w", err)
+	}
+
+	if err := c.Limits.Validate(); err != nil {
+		return fmt.Errorf("limits -> %w", err)
+	}
+
 	return nil
 }
+
+// BreakerConfig of the per-host circuit breakers. A breaker opens when at
+// least MinRequests were made to its host within Window and FailureRate of
+// them failed with a transport error or a 5xx status. It rejects requests
+// for Cooldown and then lets HalfOpenRequests (1 if 0) through: it closes
+// once they all succeed and opens again on the first failure.
+type BreakerConfig struct {
+	Enabled          bool
+	Window           time.Duration
+	MinRequests      int
+	FailureRate      float64
+	Cooldown         time.Duration
+	HalfOpenRequests int
+}
+
+func (c *BreakerConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	if c.Window <= 0 {
+		return errors.New("window must be positive")
+	}
+
+	if c.Cooldown <= 0 {
+		return errors.New("cooldown must be positive")
+	}
+
+	if c.FailureRate <= 0 || c.FailureRate > 1 {
+		return errors.New("failure rate must be in (0, 1]")
+	}
+
+	if c.MinRequests < 0 || c.HalfOpenRequests < 0 {
+		return errors.New("min requests and half-open requests cannot be negative")
+	}
+
+	return nil
+}
+
+// LimitsConfig caps the requests of the client, and separately those to
+// each host: RateLimit requests per second with bursts of Burst (1 if 0),
+// and MaxInFlight requests whose response body is not yet closed. Zero
+// leaves a limit off.
+type LimitsConfig struct {
+	RateLimit   float64
+	Burst       int
+	MaxInFlight int
+
+	HostRateLimit   float64
+	HostBurst       int
+	HostMaxInFlight int
+}
+
+func (c *LimitsConfig) Validate() error {
+	if c.RateLimit < 0 || c.HostRateLimit < 0 {
+		return errors.New("rate limits cannot be negative")
+	}
+
+	if c.Burst < 0 || c.HostBurst < 0 {
+		return errors.New("bursts cannot be negative")
+	}
+
+	if c.MaxInFlight < 0 || c.HostMaxInFlight < 0 {
+		return errors.New("max in flight cannot be negative")
+	}
+
+	return nil
+}
+
+func (c *LimitsConfig) enabled() bool {
+	return c.RateLimit > 0 || c.MaxInFlight > 0 || c.HostRateLimit > 0 || c.HostMaxInFlight > 0
+}
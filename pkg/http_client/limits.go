@@ -0,0 +1,170 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+)
+
+// ErrWaitExceedsDeadline is returned when a request would have to wait for
+// the rate limit past the deadline of its context.
+var ErrWaitExceedsDeadline = errors.New("rate limit wait would exceed deadline")
+
+var limiterWait = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "httpclient_limiter_wait_seconds",
+	Help:    "Time requests waited for the rate limit and in-flight slots, by outcome.",
+	Buckets: []float64{.001, .005, .01, .05, .1, .5, 1, 5, 10, 30},
+}, []string{"client", "host", "outcome"})
+
+// waitOutcome returns the outcome label of a wait that ended with err.
+func waitOutcome(err error) string {
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.Is(err, ErrWaitExceedsDeadline), errors.Is(err, context.DeadlineExceeded):
+		return "deadline"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	}
+	return "error"
+}
+
+// limit is one rate limit and in-flight cap; either may be nil.
+type limit struct {
+	rate  *rate.Limiter
+	slots chan struct{}
+}
+
+func newLimit(r float64, burst, maxInFlight int) *limit {
+	l := &limit{}
+	if r > 0 {
+		l.rate = rate.NewLimiter(rate.Limit(r), max(burst, 1))
+	}
+	if maxInFlight > 0 {
+		l.slots = make(chan struct{}, maxInFlight)
+	}
+	return l
+}
+
+func (l *limit) wait(ctx context.Context) error {
+	if l.rate != nil {
+		if err := l.rate.Wait(ctx); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return ErrWaitExceedsDeadline
+		}
+	}
+
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (l *limit) release() {
+	if l.slots != nil {
+		<-l.slots
+	}
+}
+
+// limiter waits for the client and host limits before sending a request
+// and holds their in-flight slots until the response body is closed.
+type limiter struct {
+	name   string
+	cfg    *LimitsConfig
+	next   http.RoundTripper
+	client *limit
+
+	mu    sync.Mutex
+	hosts map[string]*limit
+}
+
+func newLimiter(name string, cfg *LimitsConfig, next http.RoundTripper) *limiter {
+	return &limiter{
+		name:   name,
+		cfg:    cfg,
+		next:   next,
+		client: newLimit(cfg.RateLimit, cfg.Burst, cfg.MaxInFlight),
+		hosts:  make(map[string]*limit),
+	}
+}
+
+func (l *limiter) unwrap() http.RoundTripper { return l.next }
+
+func (l *limiter) host(host string) *limit {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	hl, ok := l.hosts[host]
+	if !ok {
+		hl = newLimit(l.cfg.HostRateLimit, l.cfg.HostBurst, l.cfg.HostMaxInFlight)
+		l.hosts[host] = hl
+	}
+	return hl
+}
+
+func (l *limiter) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+	hl := l.host(host)
+	ctx := req.Context()
+
+	start := time.Now()
+	err := l.acquire(ctx, host, hl)
+	limiterWait.WithLabelValues(l.name, host, waitOutcome(err)).Observe(time.Since(start).Seconds())
+	if err != nil {
+		return nil, err
+	}
+
+	release := sync.OnceFunc(func() {
+		hl.release()
+		l.client.release()
+	})
+
+	resp, err := l.next.RoundTrip(req)
+	if err != nil || resp.Body == nil {
+		release()
+		return resp, err
+	}
+
+	resp.Body = &releaseBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// acquire waits for the host limit before the client limit, so that a
+// request held back by its host does not take a client-wide slot that
+// requests to other hosts could use.
+func (l *limiter) acquire(ctx context.Context, host string, hl *limit) error {
+	if err := hl.wait(ctx); err != nil {
+		return fmt.Errorf("httpclient -> limit for %s -> %w", host, err)
+	}
+	if err := l.client.wait(ctx); err != nil {
+		hl.release()
+		return fmt.Errorf("httpclient -> client limit -> %w", err)
+	}
+	return nil
+}
+
+// releaseBody releases the in-flight slots of a request when its response
+// body is closed.
+type releaseBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
@@ -0,0 +1,227 @@
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/prometheus/client_golang/prometheus"
+	httpclient "github.com/sangrita-tech/platform-go-pkg/pkg/http_client"
+	"github.com/stretchr/testify/require"
+)
+
+func newLimitedClient(t *testing.T, limits httpclient.LimitsConfig) *retryablehttp.Client {
+	t.Helper()
+
+	c, err := httpclient.New(&httpclient.Config{
+		Timeout:      5 * time.Second,
+		RetriesMax:   3,
+		RetriesDelay: time.Millisecond,
+		Limits:       limits,
+	})
+	require.NoError(t, err)
+	return c
+}
+
+func get(ctx context.Context, c *retryablehttp.Client, url string) (*http.Response, error) {
+	req, err := retryablehttp.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(req)
+}
+
+func Test_Limits_RateLimit_SpacesRequests(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	c := newLimitedClient(t, httpclient.LimitsConfig{HostRateLimit: 20, HostBurst: 1})
+
+	start := time.Now()
+	for range 5 {
+		resp, err := c.Get(srv.URL)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+	}
+	require.GreaterOrEqual(t, time.Since(start), 180*time.Millisecond)
+}
+
+func Test_Limits_WaitPastDeadline_FailsWithoutRetry(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer srv.Close()
+
+	c := newLimitedClient(t, httpclient.LimitsConfig{RateLimit: 0.1, Burst: 1})
+
+	resp, err := c.Get(srv.URL)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	start := time.Now()
+	_, err = get(ctx, c, srv.URL)
+	require.ErrorIs(t, err, httpclient.ErrWaitExceedsDeadline)
+	require.Less(t, time.Since(start), 500*time.Millisecond)
+	require.Equal(t, int32(1), calls.Load())
+}
+
+func Test_Limits_MaxInFlight_HoldsSlotUntilBodyClosed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	c := newLimitedClient(t, httpclient.LimitsConfig{MaxInFlight: 1})
+
+	first, err := c.Get(srv.URL)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = get(ctx, c, srv.URL)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	require.NoError(t, first.Body.Close())
+
+	second, err := c.Get(srv.URL)
+	require.NoError(t, err)
+	require.NoError(t, second.Body.Close())
+}
+
+func Test_Limits_WaitTime_IsObserved(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	c := newLimitedClient(t, httpclient.LimitsConfig{HostMaxInFlight: 2})
+	resp, err := c.Get(srv.URL)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(httpclient.MetricsCollectors()...)
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+
+	var count uint64
+	for _, f := range families {
+		if f.GetName() != "httpclient_limiter_wait_seconds" {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			count += m.GetHistogram().GetSampleCount()
+		}
+	}
+	require.NotZero(t, count)
+}
+
+func Test_New_InvalidLimits_ReturnsError(t *testing.T) {
+	_, err := httpclient.New(&httpclient.Config{Timeout: time.Second, Limits: httpclient.LimitsConfig{MaxInFlight: -1}})
+	require.ErrorContains(t, err, "limits -> max in flight cannot be negative")
+}
+
+func Test_Limits_WithBreaker_RateLimitDoesNotOpenCircuit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	c, err := httpclient.New(&httpclient.Config{
+		Timeout: time.Second,
+		Breaker: httpclient.BreakerConfig{
+			Enabled:     true,
+			Window:      time.Minute,
+			MinRequests: 2,
+			FailureRate: 0.5,
+			Cooldown:    time.Minute,
+		},
+		Limits: httpclient.LimitsConfig{RateLimit: 0.1, Burst: 1},
+	})
+	require.NoError(t, err)
+
+	resp, err := c.Get(srv.URL)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	for range 3 {
+		_, err = c.Get(srv.URL)
+		require.ErrorIs(t, err, httpclient.ErrWaitExceedsDeadline)
+	}
+
+	for _, s := range httpclient.BreakersOf(c).States() {
+		require.Equal(t, httpclient.StateClosed, s)
+	}
+}
+
+func Test_Limits_FailedWait_IsObservedWithOutcome(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	c := newLimitedClient(t, httpclient.LimitsConfig{HostRateLimit: 0.1, HostBurst: 1})
+	resp, err := c.Get(srv.URL)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = get(ctx, c, srv.URL)
+	require.ErrorIs(t, err, httpclient.ErrWaitExceedsDeadline)
+
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(httpclient.MetricsCollectors()...)
+	families, err := reg.Gather()
+	require.NoError(t, err)
+
+	host := mustHost(t, srv.URL)
+	outcomes := map[string]uint64{}
+	for _, f := range families {
+		if f.GetName() != "httpclient_limiter_wait_seconds" {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			labels := map[string]string{}
+			for _, lp := range m.GetLabel() {
+				labels[lp.GetName()] = lp.GetValue()
+			}
+			if labels["host"] == host {
+				outcomes[labels["outcome"]] += m.GetHistogram().GetSampleCount()
+			}
+		}
+	}
+	require.Equal(t, map[string]uint64{"ok": 1, "deadline": 1}, outcomes)
+}
+
+func Test_Limits_WaitingOnHost_DoesNotHoldClientSlot(t *testing.T) {
+	a := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer a.Close()
+	b := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer b.Close()
+
+	c := newLimitedClient(t, httpclient.LimitsConfig{MaxInFlight: 2, HostMaxInFlight: 1})
+
+	held, err := c.Get(a.URL)
+	require.NoError(t, err)
+	defer func() { _ = held.Body.Close() }()
+
+	waitCtx, cancelWait := context.WithCancel(context.Background())
+	defer cancelWait()
+	waiting := make(chan error, 1)
+	go func() {
+		_, err := get(waitCtx, c, a.URL)
+		waiting <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	resp, err := get(ctx, c, b.URL)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	cancelWait()
+	require.ErrorIs(t, <-waiting, context.Canceled)
+}
@@ -0,0 +1,155 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+var defaultRetryStatuses = []int{
+	http.StatusRequestTimeout,
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+func newBackoff(cfg *Config) retryablehttp.Backoff {
+	return func(_, _ time.Duration, attemptNum int, resp *http.Response) time.Duration {
+		if !cfg.IgnoreRetryAfter {
+			if d, ok := retryAfter(resp); ok {
+				return capDelay(d, cfg.RetriesMaxDelay)
+			}
+		}
+
+		d := cfg.RetriesDelay
+		switch cfg.RetriesBackoff {
+		case BackoffLinear:
+			d = scaleDelay(d, float64(attemptNum+1))
+		case BackoffExponential:
+			d = scaleDelay(d, math.Pow(2, float64(attemptNum)))
+		}
+		d = capDelay(d, cfg.RetriesMaxDelay)
+
+		switch cfg.RetriesJitter {
+		case JitterFull:
+			d = rand.N(d + 1)
+		case JitterEqual:
+			d = d/2 + rand.N(d/2+1)
+		}
+		return d
+	}
+}
+
+func scaleDelay(d time.Duration, factor float64) time.Duration {
+	scaled := float64(d) * factor
+	if scaled >= math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return time.Duration(scaled)
+}
+
+func capDelay(d, limit time.Duration) time.Duration {
+	if limit > 0 && d > limit {
+		return limit
+	}
+	return d
+}
+
+// retryAfter returns the delay a 429 or 503 response asks for.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil || (resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable) {
+		return 0, false
+	}
+
+	v := strings.TrimSpace(resp.Header.Get("Retry-After"))
+	if v == "" {
+		return 0, false
+	}
+
+	if secs, err := strconv.ParseInt(v, 10, 64); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+
+	at, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+	return max(time.Until(at), 0), true
+}
+
+func newCheckRetry(cfg *Config) retryablehttp.CheckRetry {
+	statuses := cfg.RetryStatuses
+	if statuses == nil {
+		statuses = defaultRetryStatuses
+	}
+
+	return func(ctx context.Context, resp *http.Response, err error) (bool, error) {
+		if ctx.Err() != nil {
+			return false, ctx.Err()
+		}
+
+		if errors.Is(err, ErrCircuitOpen) || errors.Is(err, ErrWaitExceedsDeadline) {
+			return false, nil
+		}
+
+		if err != nil {
+			// Errors the default policy gives up on, like TLS failures,
+			// do not go away on retry.
+			if retry, _ := retryablehttp.DefaultRetryPolicy(ctx, nil, err); !retry {
+				return false, nil
+			}
+			return cfg.RetryNonIdempotent || idempotent(requestMethod(resp, err)) || notSent(err), nil
+		}
+
+		if !slices.Contains(statuses, resp.StatusCode) {
+			return false, nil
+		}
+		if cfg.RetryNonIdempotent || idempotent(resp.Request.Method) {
+			return true, nil
+		}
+		return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable, nil
+	}
+}
+
+// requestMethod returns the method of the request that failed with err,
+// which net/http only records in the url.Error it returns.
+func requestMethod(resp *http.Response, err error) string {
+	if resp != nil && resp.Request != nil {
+		return resp.Request.Method
+	}
+	var uerr *url.Error
+	if errors.As(err, &uerr) {
+		return strings.ToUpper(uerr.Op)
+	}
+	return ""
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// notSent reports whether err happened while connecting, before any of
+// the request was written.
+func notSent(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
@@ -12,7 +12,7 @@ func New(cfg *Config) (*kubernetes.Clientset, error) {
 		return nil, errors.New("kube -> config is nil")
 	}
 
-	if err := cfg.validate(); err != nil {
+	if err := cfg.Validate(); err != nil {
 		return nil, fmt.Errorf("kube -> failed to validate config -> %w", err)
 	}
 
@@ -13,7 +13,7 @@ type Config struct {
 	Timeout        time.Duration `yaml:"timeout" env:"TIMEOUT" env-default:"30s"`
 }
 
-func (c Config) validate() error {
+func (c Config) Validate() error {
 	if c.QPS < 0 {
 		return errors.New("qps must not be negative")
 	}
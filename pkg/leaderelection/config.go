@@ -3,6 +3,7 @@ package leaderelection
 import (
 	"errors"
 	"fmt"
+	"os"
 	"time"
 )
 
@@ -15,7 +16,7 @@ type Config struct {
 	RetryPeriod    time.Duration `yaml:"retryPeriod" env:"RETRY_PERIOD" env-default:"5s"`
 }
 
-func (c Config) validate() error {
+func (c Config) Validate() error {
 	if c.LeaseName == "" {
 		return errors.New("lease name must be set")
 	}
@@ -45,3 +46,14 @@ func (c Config) validate() error {
 
 	return nil
 }
+
+// IdentityOrHostname returns Identity, or the host name when it is not set.
+func (c Config) IdentityOrHostname() string {
+	if c.Identity != "" {
+		return c.Identity
+	}
+	if h, err := os.Hostname(); err == nil && h != "" {
+		return h
+	}
+	return "unknown"
+}
@@ -3,7 +3,6 @@ package leaderelection
 import (
 	"errors"
 	"fmt"
-	"os"
 
 	"k8s.io/client-go/kubernetes"
 )
@@ -24,23 +23,14 @@ func New(cfg *Config, cb Callbacks, clientset *kubernetes.Clientset) (*Elector,
 		return nil, errors.New("leaderelection -> clientset is nil")
 	}
 
-	if err := cfg.validate(); err != nil {
+	if err := cfg.Validate(); err != nil {
 		return nil, fmt.Errorf("leaderelection -> failed to validate config -> %w", err)
 	}
 
-	id := cfg.Identity
-	if id == "" {
-		if h, err := os.Hostname(); err == nil && h != "" {
-			id = h
-		} else {
-			id = "unknown"
-		}
-	}
-
 	return &Elector{
 		cfg:       cfg,
 		cb:        cb,
-		identity:  id,
+		identity:  cfg.IdentityOrHostname(),
 		clientset: clientset,
 	}, nil
 }
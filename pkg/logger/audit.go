@@ -0,0 +1,308 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"syscall"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+	OutcomeDenied  = "denied"
+)
+
+// AuditConfig is the sink of audit records, separate from the outputs of
+// Config. Type is stdout, stderr or file; records are synced before Record
+// returns, where the sink supports it. HashChain adds to every record its
+// sequence number, the hash of the previous record and its own hash,
+// continuing the chain found at the end of an existing file.
+type AuditConfig struct {
+	Type      string `yaml:"type" env:"TYPE" env-default:"stdout"`
+	Path      string `yaml:"path" env:"FILE_PATH"`
+	HashChain bool   `yaml:"hashChain" env:"HASH_CHAIN" env-default:"false"`
+}
+
+func (c *AuditConfig) Validate() error {
+	switch c.Type {
+	case OutputStdout, OutputStderr:
+	case OutputFile:
+		if c.Path == "" {
+			return errors.New("file path must be set")
+		}
+	default:
+		return fmt.Errorf("unknown type %q", c.Type)
+	}
+	return nil
+}
+
+// AuditRecord is an action taken on a resource. Actor, Action, Resource and
+// Outcome are required; Fields are added after the fixed schema and must not
+// use its keys or open a namespace, which would nest the fields after them.
+type AuditRecord struct {
+	Actor    string
+	Action   string
+	Resource string
+	Outcome  string
+	Reason   string
+	Fields   []zap.Field
+}
+
+func (r *AuditRecord) validate() error {
+	if r.Actor == "" || r.Action == "" || r.Resource == "" {
+		return errors.New("actor, action and resource must be set")
+	}
+	switch r.Outcome {
+	case OutcomeSuccess, OutcomeFailure, OutcomeDenied:
+	default:
+		return fmt.Errorf("unknown outcome %q", r.Outcome)
+	}
+	for _, f := range r.Fields {
+		if _, ok := auditReserved[f.Key]; ok {
+			return fmt.Errorf("field %q is reserved", f.Key)
+		}
+		if f.Type == zapcore.NamespaceType {
+			return fmt.Errorf("field %q opens a namespace", f.Key)
+		}
+	}
+	return nil
+}
+
+// auditReserved are the keys of the fixed schema.
+var auditReserved = map[string]struct{}{
+	"time": {}, "actor": {}, "action": {}, "resource": {}, "outcome": {}, "reason": {},
+	"seq": {}, "prev_hash": {}, "hash": {}, "trace_id": {}, "span_id": {},
+}
+
+// Audit writes audit records. It does not use the level, sampling or
+// outputs of New.
+type Audit struct {
+	mu    sync.Mutex
+	enc   zapcore.Encoder
+	w     io.Writer
+	sync  func() error
+	chain bool
+	seq   uint64
+	prev  string
+}
+
+func NewAudit(cfg *AuditConfig) (*Audit, func(), error) {
+	if cfg == nil {
+		return nil, nil, errors.New("logger -> audit -> config is nil")
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("logger -> audit -> failed to validate config -> %w", err)
+	}
+
+	a := &Audit{enc: zapcore.NewJSONEncoder(auditEncoderConfig()), chain: cfg.HashChain}
+
+	switch cfg.Type {
+	case OutputStdout:
+		a.w, a.sync = os.Stdout, syncStd(os.Stdout)
+		return a, func() {}, nil
+	case OutputStderr:
+		a.w, a.sync = os.Stderr, syncStd(os.Stderr)
+		return a, func() {}, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cfg.Path), 0o755); err != nil {
+		return nil, nil, fmt.Errorf("logger -> audit -> failed to create dir -> %w", err)
+	}
+
+	f, err := os.OpenFile(cfg.Path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, nil, fmt.Errorf("logger -> audit -> failed to open file -> %w", err)
+	}
+
+	if cfg.HashChain {
+		if a.seq, a.prev, err = lastChainLink(f); err != nil {
+			_ = f.Close()
+			return nil, nil, fmt.Errorf("logger -> audit -> failed to read chain -> %w", err)
+		}
+	}
+
+	a.w, a.sync = f, f.Sync
+	return a, func() { _ = f.Close() }, nil
+}
+
+// syncStd syncs f, ignoring the errors of pipes and terminals, which cannot
+// be synced.
+func syncStd(f *os.File) func() error {
+	return func() error {
+		if err := f.Sync(); err != nil && !errors.Is(err, syscall.EINVAL) && !errors.Is(err, syscall.ENOTSUP) {
+			return err
+		}
+		return nil
+	}
+}
+
+// Record writes rec with the trace_id and span_id of the span in ctx and
+// returns once it is synced.
+func (a *Audit) Record(ctx context.Context, rec AuditRecord) error {
+	if err := rec.validate(); err != nil {
+		return fmt.Errorf("logger -> audit -> invalid record -> %w", err)
+	}
+
+	fields := []zap.Field{
+		zap.String("actor", rec.Actor),
+		zap.String("action", rec.Action),
+		zap.String("resource", rec.Resource),
+		zap.String("outcome", rec.Outcome),
+	}
+	if rec.Reason != "" {
+		fields = append(fields, zap.String("reason", rec.Reason))
+	}
+	fields = append(fields, rec.Fields...)
+
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		fields = append(fields, zap.String("trace_id", sc.TraceID().String()), zap.String("span_id", sc.SpanID().String()))
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.chain {
+		fields = append(fields, zap.Uint64("seq", a.seq+1), zap.String("prev_hash", a.prev))
+	}
+
+	buf, err := a.enc.EncodeEntry(zapcore.Entry{Time: time.Now()}, fields)
+	if err != nil {
+		return fmt.Errorf("logger -> audit -> failed to encode record -> %w", err)
+	}
+	defer buf.Free()
+
+	line := buf.Bytes()
+	var hash string
+	if a.chain {
+		body := bytes.TrimSuffix(line, []byte("}\n"))
+		hash = chainHash(a.prev, body)
+		line = fmt.Appendf(body[:len(body):len(body)], `,"hash":"%s"}`+"\n", hash)
+	}
+
+	if _, err := a.w.Write(line); err != nil {
+		return fmt.Errorf("logger -> audit -> failed to write record -> %w", err)
+	}
+	if err := a.sync(); err != nil {
+		return fmt.Errorf("logger -> audit -> failed to sync record -> %w", err)
+	}
+
+	if a.chain {
+		a.seq++
+		a.prev = hash
+	}
+	return nil
+}
+
+var hashSuffix = regexp.MustCompile(`,"hash":"([0-9a-f]{64})"}$`)
+
+// VerifyAudit checks the hash chain of the records read from r and returns
+// the number of records verified.
+func VerifyAudit(r io.Reader) (int, error) {
+	var prev string
+	n := 0
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for sc.Scan() {
+		line := sc.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		n++
+
+		m := hashSuffix.FindSubmatchIndex(line)
+		if m == nil {
+			return n - 1, fmt.Errorf("logger -> audit -> record %d has no hash", n)
+		}
+		body, hash := line[:m[0]], string(line[m[2]:m[3]])
+
+		var rec struct {
+			Seq      uint64 `json:"seq"`
+			PrevHash string `json:"prev_hash"`
+		}
+		if err := json.Unmarshal(append(body[:len(body):len(body)], '}'), &rec); err != nil {
+			return n - 1, fmt.Errorf("logger -> audit -> record %d -> %w", n, err)
+		}
+		if rec.PrevHash != prev {
+			return n - 1, fmt.Errorf("logger -> audit -> record %d (seq %d) does not follow the previous record", n, rec.Seq)
+		}
+		if chainHash(prev, body) != hash {
+			return n - 1, fmt.Errorf("logger -> audit -> record %d (seq %d) was modified", n, rec.Seq)
+		}
+		prev = hash
+	}
+
+	if err := sc.Err(); err != nil {
+		return n, fmt.Errorf("logger -> audit -> failed to read records -> %w", err)
+	}
+	return n, nil
+}
+
+func chainHash(prev string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(prev))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// lastChainLink returns the seq and hash of the last record of f.
+func lastChainLink(f *os.File) (uint64, string, error) {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return 0, "", err
+	}
+
+	var last []byte
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for sc.Scan() {
+		if line := bytes.TrimSpace(sc.Bytes()); len(line) > 0 {
+			last = append(last[:0], line...)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return 0, "", err
+	}
+	if last == nil {
+		return 0, "", nil
+	}
+
+	var rec struct {
+		Seq  uint64 `json:"seq"`
+		Hash string `json:"hash"`
+	}
+	if err := json.Unmarshal(last, &rec); err != nil {
+		return 0, "", err
+	}
+	if rec.Hash == "" {
+		return 0, "", errors.New("last record has no hash")
+	}
+	return rec.Seq, rec.Hash, nil
+}
+
+func auditEncoderConfig() zapcore.EncoderConfig {
+	return zapcore.EncoderConfig{
+		TimeKey:    "time",
+		LineEnding: zapcore.DefaultLineEnding,
+		EncodeTime: func(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+			enc.AppendString(t.UTC().Format(time.RFC3339Nano))
+		},
+		EncodeDuration: zapcore.StringDurationEncoder,
+	}
+}
@@ -0,0 +1,152 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sangrita-tech/platform-go-pkg/pkg/logger"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func scaleRecord(outcome string) logger.AuditRecord {
+	return logger.AuditRecord{
+		Actor:    "system:serviceaccount:ops:controller",
+		Action:   "scale",
+		Resource: "apps/v1/namespaces/shop/deployments/api",
+		Outcome:  outcome,
+		Reason:   "queue length above target",
+		Fields:   []zap.Field{zap.Int("replicas", 5)},
+	}
+}
+
+func Test_Audit_Record_WritesFixedSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	a, cleanup, err := logger.NewAudit(&logger.AuditConfig{Type: logger.OutputFile, Path: path})
+	require.NoError(t, err)
+	defer cleanup()
+
+	require.NoError(t, a.Record(context.Background(), scaleRecord(logger.OutcomeSuccess)))
+
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var m map[string]any
+	require.NoError(t, json.Unmarshal(raw, &m))
+	require.Equal(t, "system:serviceaccount:ops:controller", m["actor"])
+	require.Equal(t, "scale", m["action"])
+	require.Equal(t, "apps/v1/namespaces/shop/deployments/api", m["resource"])
+	require.Equal(t, "success", m["outcome"])
+	require.Equal(t, "queue length above target", m["reason"])
+	require.Equal(t, float64(5), m["replicas"])
+	require.Contains(t, m, "time")
+	require.NotContains(t, m, "level")
+	require.NotContains(t, m, "hash")
+}
+
+func Test_Audit_Record_InvalidRecord_ReturnsError(t *testing.T) {
+	a, cleanup, err := logger.NewAudit(&logger.AuditConfig{Type: logger.OutputFile, Path: filepath.Join(t.TempDir(), "audit.log")})
+	require.NoError(t, err)
+	defer cleanup()
+
+	require.ErrorContains(t, a.Record(context.Background(), logger.AuditRecord{Action: "scale"}), "actor, action and resource must be set")
+	require.ErrorContains(t, a.Record(context.Background(), scaleRecord("maybe")), `unknown outcome "maybe"`)
+
+	rec := scaleRecord(logger.OutcomeDenied)
+	rec.Fields = append(rec.Fields, zap.String("outcome", logger.OutcomeSuccess))
+	require.ErrorContains(t, a.Record(context.Background(), rec), `field "outcome" is reserved`)
+}
+
+func Test_Audit_Record_NamespaceField_ReturnsErrorAndKeepsChain(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	a, cleanup, err := logger.NewAudit(&logger.AuditConfig{Type: logger.OutputFile, Path: path, HashChain: true})
+	require.NoError(t, err)
+
+	rec := scaleRecord(logger.OutcomeSuccess)
+	rec.Fields = append(rec.Fields, zap.Namespace("details"), zap.Int("from", 3))
+	require.ErrorContains(t, a.Record(context.Background(), rec), `field "details" opens a namespace`)
+	require.NoError(t, a.Record(context.Background(), scaleRecord(logger.OutcomeSuccess)))
+	require.NoError(t, a.Record(context.Background(), scaleRecord(logger.OutcomeSuccess)))
+	cleanup()
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	n, err := logger.VerifyAudit(f)
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+}
+
+func Test_Audit_Record_Stdout_SyncsWithoutError(t *testing.T) {
+	a, cleanup, err := logger.NewAudit(&logger.AuditConfig{Type: logger.OutputStdout})
+	require.NoError(t, err)
+	defer cleanup()
+
+	require.NoError(t, a.Record(context.Background(), scaleRecord(logger.OutcomeSuccess)))
+}
+
+func Test_Audit_HashChain_VerifiesAndContinuesAcrossReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	cfg := &logger.AuditConfig{Type: logger.OutputFile, Path: path, HashChain: true}
+
+	a, cleanup, err := logger.NewAudit(cfg)
+	require.NoError(t, err)
+	require.NoError(t, a.Record(context.Background(), scaleRecord(logger.OutcomeSuccess)))
+	require.NoError(t, a.Record(context.Background(), scaleRecord(logger.OutcomeDenied)))
+	cleanup()
+
+	a, cleanup, err = logger.NewAudit(cfg)
+	require.NoError(t, err)
+	require.NoError(t, a.Record(context.Background(), scaleRecord(logger.OutcomeFailure)))
+	cleanup()
+
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	n, err := logger.VerifyAudit(bytes.NewReader(raw))
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+
+	lines := strings.Split(strings.TrimSpace(string(raw)), "\n")
+	var last map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[2]), &last))
+	require.Equal(t, float64(3), last["seq"])
+}
+
+func Test_VerifyAudit_TamperedRecord_ReturnsError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	a, cleanup, err := logger.NewAudit(&logger.AuditConfig{Type: logger.OutputFile, Path: path, HashChain: true})
+	require.NoError(t, err)
+	require.NoError(t, a.Record(context.Background(), scaleRecord(logger.OutcomeDenied)))
+	require.NoError(t, a.Record(context.Background(), scaleRecord(logger.OutcomeSuccess)))
+	cleanup()
+
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	tampered := strings.Replace(string(raw), `"outcome":"denied"`, `"outcome":"success"`, 1)
+	n, err := logger.VerifyAudit(strings.NewReader(tampered))
+	require.ErrorContains(t, err, "record 1 (seq 1) was modified")
+	require.Equal(t, 0, n)
+
+	lines := strings.SplitAfter(string(raw), "\n")
+	n, err = logger.VerifyAudit(strings.NewReader(lines[1]))
+	require.ErrorContains(t, err, "does not follow the previous record")
+	require.Equal(t, 0, n)
+}
+
+func Test_NewAudit_InvalidConfig_ReturnsError(t *testing.T) {
+	_, _, err := logger.NewAudit(nil)
+	require.Error(t, err)
+
+	_, _, err = logger.NewAudit(&logger.AuditConfig{Type: logger.OutputFile})
+	require.ErrorContains(t, err, "logger -> audit -> failed to validate config")
+
+	_, _, err = logger.NewAudit(&logger.AuditConfig{Type: logger.OutputSyslog})
+	require.ErrorContains(t, err, "unknown type")
+}
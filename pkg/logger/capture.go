@@ -3,13 +3,88 @@ package logger
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
 	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
 )
 
+// Entry is a captured log entry. Fields holds everything but the standard
+// keys, with values as decoded from JSON.
+type Entry struct {
+	Level   zapcore.Level
+	Message string
+	Time    time.Time
+	Logger  string
+	Caller  string
+	Stack   string
+	Fields  map[string]any
+}
+
+type Entries []Entry
+
+func (es Entries) Filter(pred func(Entry) bool) Entries {
+	var out Entries
+	for _, e := range es {
+		if pred(e) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func (es Entries) ByLevel(lvl zapcore.Level) Entries {
+	return es.Filter(func(e Entry) bool { return e.Level == lvl })
+}
+
+func (es Entries) WithMessage(msg string) Entries {
+	return es.Filter(func(e Entry) bool { return e.Message == msg })
+}
+
+func (es Entries) WithField(key string, value any) Entries {
+	return es.Filter(HasField(key, value))
+}
+
+func (es Entries) Messages() []string {
+	out := make([]string, len(es))
+	for i, e := range es {
+		out[i] = e.Message
+	}
+	return out
+}
+
+// HasField matches entries whose field key equals value once value is
+// converted the way JSON decoding converts it, so HasField("n", 1) matches
+// a decoded 1.0.
+func HasField(key string, value any) func(Entry) bool {
+	want := jsonValue(value)
+	return func(e Entry) bool {
+		got, ok := e.Fields[key]
+		return ok && reflect.DeepEqual(got, want)
+	}
+}
+
+func jsonValue(v any) any {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var out any
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return v
+	}
+	return out
+}
+
 type Capture struct {
 	mu        sync.Mutex
 	entries   []map[string]any
 	remainder []byte
+	changed   chan struct{}
+	text      bytes.Buffer
 }
 
 func (c *Capture) Write(p []byte) (int, error) {
@@ -35,6 +110,11 @@ func (c *Capture) Write(p []byte) (int, error) {
 		}
 	}
 
+	if c.changed != nil {
+		close(c.changed)
+		c.changed = nil
+	}
+
 	return len(p), nil
 }
 
@@ -49,9 +129,154 @@ func (c *Capture) All() []map[string]any {
 	return out
 }
 
+// Entries returns the captured entries in the order they were written.
+func (c *Capture) Entries() Entries {
+	all := c.All()
+	out := make(Entries, len(all))
+	for i, m := range all {
+		out[i] = toEntry(m)
+	}
+	return out
+}
+
+// Text returns the output as written in the configured Format.
+func (c *Capture) Text() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.text.String()
+}
+
 func (c *Capture) Reset() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	c.entries = nil
 	c.remainder = nil
+	c.text.Reset()
+}
+
+// WaitFor waits up to timeout for an entry matching pred to be written and
+// returns it.
+func (c *Capture) WaitFor(pred func(Entry) bool, timeout time.Duration) (Entry, bool) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		c.mu.Lock()
+		if c.changed == nil {
+			c.changed = make(chan struct{})
+		}
+		changed := c.changed
+		c.mu.Unlock()
+
+		if es := c.Entries().Filter(pred); len(es) > 0 {
+			return es[0], true
+		}
+
+		select {
+		case <-changed:
+		case <-timer.C:
+			return Entry{}, false
+		}
+	}
+}
+
+// TestingT is the part of *testing.T the assertion helpers use.
+type TestingT interface {
+	Errorf(format string, args ...any)
+}
+
+// AssertLogged reports an error to t unless an entry with lvl and msg was
+// captured.
+func (c *Capture) AssertLogged(t TestingT, lvl zapcore.Level, msg string) bool {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+
+	if len(c.Entries().ByLevel(lvl).WithMessage(msg)) > 0 {
+		return true
+	}
+	t.Errorf("no %s entry %q was logged, got:\n%s", lvl.CapitalString(), msg, c.summary())
+	return false
+}
+
+// AssertNotLogged reports an error to t if an entry with lvl and msg was
+// captured.
+func (c *Capture) AssertNotLogged(t TestingT, lvl zapcore.Level, msg string) bool {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+
+	if len(c.Entries().ByLevel(lvl).WithMessage(msg)) == 0 {
+		return true
+	}
+	t.Errorf("%s entry %q was logged", lvl.CapitalString(), msg)
+	return false
+}
+
+// AssertField reports an error to t unless an entry with msg has the field
+// key set to value.
+func (c *Capture) AssertField(t TestingT, msg, key string, value any) bool {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+
+	es := c.Entries().WithMessage(msg)
+	if len(es) == 0 {
+		t.Errorf("no entry %q was logged, got:\n%s", msg, c.summary())
+		return false
+	}
+	if len(es.WithField(key, value)) > 0 {
+		return true
+	}
+	t.Errorf("entry %q has %s = %v, want %v", msg, key, es[0].Fields[key], value)
+	return false
+}
+
+func (c *Capture) summary() string {
+	var b strings.Builder
+	for _, e := range c.Entries() {
+		fmt.Fprintf(&b, "\t%s %q %v\n", e.Level.CapitalString(), e.Message, e.Fields)
+	}
+	return b.String()
+}
+
+func (c *Capture) textWriter() zapcore.WriteSyncer {
+	return zapcore.AddSync(captureText{c})
+}
+
+type captureText struct {
+	c *Capture
+}
+
+func (w captureText) Write(p []byte) (int, error) {
+	w.c.mu.Lock()
+	defer w.c.mu.Unlock()
+	return w.c.text.Write(p)
+}
+
+func toEntry(m map[string]any) Entry {
+	var e Entry
+	e.Fields = make(map[string]any, len(m))
+
+	enc := encoderConfigUTC()
+	for k, v := range m {
+		s, _ := v.(string)
+		switch k {
+		case enc.LevelKey:
+			_ = e.Level.UnmarshalText([]byte(s))
+		case enc.MessageKey:
+			e.Message = s
+		case enc.TimeKey:
+			e.Time, _ = time.Parse(timeLayout, s)
+		case enc.NameKey:
+			e.Logger = s
+		case enc.CallerKey:
+			e.Caller = s
+		case enc.StacktraceKey:
+			e.Stack = s
+		default:
+			e.Fields[k] = v
+		}
+	}
+	return e
 }
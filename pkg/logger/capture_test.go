@@ -0,0 +1,100 @@
+package logger_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sangrita-tech/platform-go-pkg/pkg/logger"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type recordingT struct {
+	errors []string
+}
+
+func (t *recordingT) Errorf(format string, args ...any) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func Test_Capture_Entries_FiltersTypedEntries(t *testing.T) {
+	l, capture, err := logger.NewInMemory(&logger.Config{Level: "debug", Format: "json"})
+	require.NoError(t, err)
+
+	l.Named("sync").Debug("listing", zap.Int("page", 1))
+	l.Warn("slow", zap.Duration("took", time.Second))
+	l.Warn("slow", zap.Duration("took", 2*time.Second))
+
+	entries := capture.Entries()
+	require.Len(t, entries, 3)
+	require.Equal(t, zapcore.DebugLevel, entries[0].Level)
+	require.Equal(t, "sync", entries[0].Logger)
+	require.Contains(t, entries[0].Caller, "capture_test.go:")
+	require.WithinDuration(t, time.Now(), entries[0].Time, time.Minute)
+
+	require.Len(t, entries.ByLevel(zapcore.WarnLevel), 2)
+	require.Equal(t, []string{"listing"}, entries.WithField("page", 1).Messages())
+	require.Len(t, entries.WithMessage("slow").WithField("took", 2*time.Second), 1)
+}
+
+func Test_Capture_WaitFor_ReturnsEntryWrittenLater(t *testing.T) {
+	l, capture, err := logger.NewInMemory(&logger.Config{Level: "info", Format: "json"})
+	require.NoError(t, err)
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		l.Info("done", zap.String("job", "a"))
+	}()
+
+	e, ok := capture.WaitFor(logger.HasField("job", "a"), time.Second)
+	require.True(t, ok)
+	require.Equal(t, "done", e.Message)
+
+	_, ok = capture.WaitFor(logger.HasField("job", "b"), 20*time.Millisecond)
+	require.False(t, ok)
+}
+
+func Test_Capture_Assertions_ReportFailures(t *testing.T) {
+	l, capture, err := logger.NewInMemory(&logger.Config{Level: "info", Format: "json"})
+	require.NoError(t, err)
+	l.Error("failed", zap.String("reason", "timeout"))
+
+	rt := &recordingT{}
+	require.True(t, capture.AssertLogged(rt, zapcore.ErrorLevel, "failed"))
+	require.True(t, capture.AssertNotLogged(rt, zapcore.InfoLevel, "failed"))
+	require.True(t, capture.AssertField(rt, "failed", "reason", "timeout"))
+	require.Empty(t, rt.errors)
+
+	require.False(t, capture.AssertLogged(rt, zapcore.WarnLevel, "failed"))
+	require.False(t, capture.AssertField(rt, "failed", "reason", "refused"))
+	require.Len(t, rt.errors, 2)
+	require.Contains(t, rt.errors[0], `no WARN entry "failed" was logged`)
+}
+
+func Test_NewInMemory_ConsoleFormat_CapturesTextAndEntries(t *testing.T) {
+	l, capture, err := logger.NewInMemory(&logger.Config{Level: "info", Format: "console"})
+	require.NoError(t, err)
+
+	l.Info("hello", zap.String("k", "v"))
+
+	require.Contains(t, capture.Text(), "INFO")
+	require.Contains(t, capture.Text(), "\thello\t")
+	require.Len(t, capture.Entries().WithField("k", "v"), 1)
+}
+
+func Test_NewInMemory_InvalidConfig_ReturnsError(t *testing.T) {
+	_, _, err := logger.NewInMemory(nil)
+	require.Error(t, err)
+
+	_, _, err = logger.NewInMemory(&logger.Config{Level: "loud", Format: "json"})
+	require.ErrorContains(t, err, "logger -> failed to validate config")
+}
+
+func Test_NewInMemory_DevMode_PanicsOnDPanic(t *testing.T) {
+	l, _, err := logger.NewInMemory(&logger.Config{Level: "info", Format: "json", DevMode: true})
+	require.NoError(t, err)
+
+	require.Panics(t, func() { l.DPanic("invariant broken") })
+}
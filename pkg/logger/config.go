@@ -1,8 +1,10 @@
 package logger
 
 import (
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 )
 
 type Config struct {
@@ -10,20 +12,100 @@ type Config struct {
 	Format     string            `json:"format" yaml:"format" env:"FORMAT" env-default:"json"`
 	DevMode    bool              `yaml:"devMode" env:"DEV_MODE" env-default:"false"`
 	BaseFields map[string]string `yaml:"baseFields" env:"BASE_FIELDS"`
+	Levels     map[string]string `yaml:"levels" env:"LEVELS"`
+	Sampling   SamplingConfig    `yaml:"sampling" env-prefix:"SAMPLING_"`
+	Outputs    []OutputConfig    `yaml:"outputs"`
+	Async      AsyncConfig       `yaml:"async" env-prefix:"ASYNC_"`
+	Trace      TraceConfig       `yaml:"trace" env-prefix:"TRACE_"`
+	Redaction  RedactionConfig   `yaml:"redaction" env-prefix:"REDACT_"`
+	Encoding   EncodingConfig    `yaml:"encoding" env-prefix:"ENCODING_"`
+	// StackDepth limits the stack frames logged per level, for example
+	// {warn: 5, error: 20}; the limit of the highest listed level at or
+	// below an entry's applies and 0 drops stacks.
+	StackDepth map[string]int `yaml:"stackDepth" env:"STACK_DEPTH"`
 }
 
-func (c *Config) validate() error {
-	switch strings.ToLower(c.Level) {
-	case "debug", "info", "warn", "warning", "error":
-	default:
+// SamplingConfig limits repeated entries with the same level and message.
+// Within each Tick the first Initial are written and then every
+// Thereafter-th, or none if it is 0; Initial 0 disables sampling. RateLimit
+// caps the entries written per message and Tick; 0 disables it. With
+// ExemptErrors, error and higher entries bypass both. Dropped entries are
+// summarized every ReportInterval.
+type SamplingConfig struct {
+	Initial        int           `yaml:"initial" env:"INITIAL" env-default:"0"`
+	Thereafter     int           `yaml:"thereafter" env:"THEREAFTER" env-default:"0"`
+	Tick           time.Duration `yaml:"tick" env:"TICK" env-default:"1s"`
+	RateLimit      int           `yaml:"rateLimit" env:"RATE_LIMIT" env-default:"0"`
+	ExemptErrors   bool          `yaml:"exemptErrors" env:"EXEMPT_ERRORS" env-default:"false"`
+	ReportInterval time.Duration `yaml:"reportInterval" env:"REPORT_INTERVAL" env-default:"1m"`
+}
+
+func (c *SamplingConfig) Validate() error {
+	if c.Initial < 0 || c.Thereafter < 0 || c.RateLimit < 0 {
+		return errors.New("initial, thereafter and rate limit must be >= 0")
+	}
+
+	if (c.Initial > 0 || c.RateLimit > 0) && c.Tick <= 0 {
+		return errors.New("tick must be > 0")
+	}
+
+	if c.ReportInterval < 0 {
+		return errors.New("report interval must be >= 0")
+	}
+
+	return nil
+}
+
+func (c *Config) Validate() error {
+	if _, ok := lookupLevel(c.Level); !ok {
 		return fmt.Errorf("unknown level %q", c.Level)
 	}
 
+	for name, level := range c.Levels {
+		if name == "" {
+			return errors.New("levels -> logger name is empty")
+		}
+		if _, ok := lookupLevel(level); !ok {
+			return fmt.Errorf("levels -> unknown level %q for %s", level, name)
+		}
+	}
+
+	for name, depth := range c.StackDepth {
+		if _, ok := lookupLevel(name); !ok {
+			return fmt.Errorf("stack depth -> unknown level %q", name)
+		}
+		if depth < 0 {
+			return fmt.Errorf("stack depth -> %s must be >= 0", name)
+		}
+	}
+
 	switch strings.ToLower(c.Format) {
 	case "json", "console":
 	default:
 		return fmt.Errorf("unknown format %q", c.Format)
 	}
 
+	if err := c.Sampling.Validate(); err != nil {
+		return fmt.Errorf("sampling -> %w", err)
+	}
+
+	for i := range c.Outputs {
+		if err := c.Outputs[i].Validate(); err != nil {
+			return fmt.Errorf("outputs -> %d -> %w", i, err)
+		}
+	}
+
+	if err := c.Async.Validate(); err != nil {
+		return fmt.Errorf("async -> %w", err)
+	}
+
+	if err := c.Redaction.Validate(); err != nil {
+		return fmt.Errorf("redaction -> %w", err)
+	}
+
+	if err := c.Encoding.Validate(); err != nil {
+		return fmt.Errorf("encoding -> %w", err)
+	}
+
 	return nil
 }
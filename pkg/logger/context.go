@@ -0,0 +1,75 @@
+package logger
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/zapr"
+	"go.uber.org/zap"
+	klog "k8s.io/klog/v2"
+)
+
+const (
+	FieldRequestID      = "request_id"
+	FieldTenant         = "tenant"
+	FieldLeaderIdentity = "leader_identity"
+)
+
+type ctxKey struct{}
+
+var global atomic.Pointer[zap.Logger]
+
+// L returns the logger last built by New, or zap.L() before that.
+func L() *zap.Logger {
+	if l := global.Load(); l != nil {
+		return l
+	}
+	return zap.L()
+}
+
+// WithContext returns a copy of ctx carrying l. The same logger is stored
+// for klog, so klog.FromContext(ctx) writes through l as well.
+func WithContext(ctx context.Context, l *zap.Logger) context.Context {
+	ctx = context.WithValue(ctx, ctxKey{}, l)
+	return klog.NewContext(ctx, zapr.NewLogger(l))
+}
+
+// FromContext returns the logger carried by ctx. A zapr logger stored with
+// klog.NewContext is used too; otherwise it falls back to L. If ctx carries
+// an OpenTelemetry span, the returned logger adds its trace and span ids to
+// every entry as configured by Config.Trace.
+func FromContext(ctx context.Context) *zap.Logger {
+	return withSpan(ctx, contextLogger(ctx))
+}
+
+func contextLogger(ctx context.Context) *zap.Logger {
+	if l, ok := ctx.Value(ctxKey{}).(*zap.Logger); ok {
+		return l
+	}
+
+	if lg, err := logr.FromContext(ctx); err == nil {
+		if u, ok := lg.GetSink().(zapr.Underlier); ok {
+			return u.GetUnderlying()
+		}
+	}
+
+	return L()
+}
+
+// With returns a copy of ctx whose logger has fields added.
+func With(ctx context.Context, fields ...zap.Field) context.Context {
+	return WithContext(ctx, contextLogger(ctx).With(fields...))
+}
+
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return With(ctx, zap.String(FieldRequestID, id))
+}
+
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return With(ctx, zap.String(FieldTenant, tenant))
+}
+
+func WithLeaderIdentity(ctx context.Context, identity string) context.Context {
+	return With(ctx, zap.String(FieldLeaderIdentity, identity))
+}
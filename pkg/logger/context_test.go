@@ -0,0 +1,55 @@
+package logger_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/zapr"
+	"github.com/sangrita-tech/platform-go-pkg/pkg/logger"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	klog "k8s.io/klog/v2"
+)
+
+func Test_FromContext_NoLogger_ReturnsGlobal(t *testing.T) {
+	l, cleanup, err := logger.New(&logger.Config{Level: "info", Format: "json"})
+	require.NoError(t, err)
+	defer cleanup()
+
+	require.Same(t, l, logger.FromContext(context.Background()))
+	require.Same(t, l, logger.L())
+}
+
+func Test_With_AddsFieldsToContextLogger(t *testing.T) {
+	l, capture, err := logger.NewInMemory(&logger.Config{Level: "info", Format: "json"})
+	require.NoError(t, err)
+
+	ctx := logger.WithContext(context.Background(), l)
+	ctx = logger.WithRequestID(ctx, "req-1")
+	ctx = logger.WithTenant(ctx, "acme")
+	ctx = logger.WithLeaderIdentity(ctx, "pod-a")
+
+	logger.FromContext(ctx).Info("handled")
+
+	entries := capture.All()
+	require.Len(t, entries, 1)
+	require.Equal(t, "req-1", entries[0][logger.FieldRequestID])
+	require.Equal(t, "acme", entries[0][logger.FieldTenant])
+	require.Equal(t, "pod-a", entries[0][logger.FieldLeaderIdentity])
+}
+
+func Test_WithContext_SharedWithKlog(t *testing.T) {
+	l, capture, err := logger.NewInMemory(&logger.Config{Level: "info", Format: "json"})
+	require.NoError(t, err)
+
+	ctx := logger.WithRequestID(logger.WithContext(context.Background(), l), "req-2")
+	klog.FromContext(ctx).Info("from klog")
+
+	ctx = klog.NewContext(context.Background(), zapr.NewLogger(l.With(zap.String("component", "kube"))))
+	logger.FromContext(ctx).Info("from zap")
+
+	entries := capture.All()
+	require.Len(t, entries, 2)
+	require.Equal(t, "req-2", entries[0][logger.FieldRequestID])
+	require.Equal(t, "kube", entries[1]["component"])
+}
@@ -0,0 +1,263 @@
+package logger
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	PresetDefault = "default"
+	PresetECS     = "ecs"
+	PresetGCP     = "gcp"
+	PresetOTel    = "otel"
+)
+
+const (
+	TimeRFC3339      = "rfc3339"
+	TimeRFC3339Milli = "rfc3339milli"
+	TimeRFC3339Nano  = "rfc3339nano"
+	TimeEpoch        = "epoch"
+	TimeEpochMillis  = "epochMillis"
+	TimeEpochNanos   = "epochNanos"
+)
+
+// Keys that EncodingConfig.Keys can rename.
+const (
+	KeyTime     = "time"
+	KeyLevel    = "level"
+	KeyMessage  = "message"
+	KeyLogger   = "logger"
+	KeyCaller   = "caller"
+	KeyFunction = "function"
+	KeyStack    = "stack"
+)
+
+const ecsVersion = "1.6.0"
+
+// EncodingConfig selects the schema of encoded entries. Preset is one of
+// default, ecs (Elastic Common Schema), gcp (Cloud Logging structured
+// logging) or otel (OpenTelemetry log data model field names). Keys renames
+// the keys of the preset, for example {message: msg}; an empty name drops
+// the key. TimeFormat overrides the preset's timestamp format.
+type EncodingConfig struct {
+	Preset     string            `yaml:"preset" env:"PRESET" env-default:"default"`
+	Keys       map[string]string `yaml:"keys" env:"KEYS"`
+	TimeFormat string            `yaml:"timeFormat" env:"TIME_FORMAT"`
+}
+
+func (c *EncodingConfig) Validate() error {
+	switch c.Preset {
+	case "", PresetDefault, PresetECS, PresetGCP, PresetOTel:
+	default:
+		return fmt.Errorf("unknown preset %q", c.Preset)
+	}
+
+	for k := range c.Keys {
+		switch k {
+		case KeyTime, KeyLevel, KeyMessage, KeyLogger, KeyCaller, KeyFunction, KeyStack:
+		default:
+			return fmt.Errorf("unknown key %q", k)
+		}
+	}
+
+	if c.TimeFormat != "" && timeEncoder(c.TimeFormat) == nil {
+		return fmt.Errorf("unknown time format %q", c.TimeFormat)
+	}
+
+	return nil
+}
+
+// encoding is the encoder config of a preset and the fields it adds to
+// every entry.
+type encoding struct {
+	cfg   zapcore.EncoderConfig
+	extra func(ent zapcore.Entry) []zapcore.Field
+}
+
+func newEncoding(c *EncodingConfig) encoding {
+	e := encoding{cfg: encoderConfigUTC()}
+	timeFormat := TimeRFC3339Milli
+
+	switch c.Preset {
+	case PresetECS:
+		e.cfg.TimeKey = "@timestamp"
+		e.cfg.LevelKey = "log.level"
+		e.cfg.MessageKey = "message"
+		e.cfg.NameKey = "log.logger"
+		e.cfg.CallerKey = zapcore.OmitKey
+		e.cfg.FunctionKey = "log.origin.function"
+		e.cfg.StacktraceKey = "error.stack_trace"
+		e.cfg.EncodeLevel = zapcore.LowercaseLevelEncoder
+		e.extra = ecsFields
+	case PresetGCP:
+		e.cfg.TimeKey = "time"
+		e.cfg.LevelKey = "severity"
+		e.cfg.MessageKey = "message"
+		e.cfg.CallerKey = zapcore.OmitKey
+		e.cfg.StacktraceKey = "stack_trace"
+		e.cfg.EncodeLevel = func(l zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+			enc.AppendString(gcpSeverity(l))
+		}
+		e.extra = gcpSourceLocation
+		timeFormat = TimeRFC3339Nano
+	case PresetOTel:
+		e.cfg.TimeKey = "timestamp"
+		e.cfg.LevelKey = "severity_text"
+		e.cfg.MessageKey = "body"
+		e.cfg.NameKey = "scope_name"
+		e.cfg.CallerKey = "code.filepath"
+		e.cfg.FunctionKey = "code.function"
+		e.cfg.StacktraceKey = "exception.stacktrace"
+		e.extra = func(ent zapcore.Entry) []zapcore.Field {
+			return []zapcore.Field{zap.Int("severity_number", otelSeverity(ent.Level))}
+		}
+		timeFormat = TimeEpochNanos
+	}
+
+	for k, name := range c.Keys {
+		switch k {
+		case KeyTime:
+			e.cfg.TimeKey = name
+		case KeyLevel:
+			e.cfg.LevelKey = name
+		case KeyMessage:
+			e.cfg.MessageKey = name
+		case KeyLogger:
+			e.cfg.NameKey = name
+		case KeyCaller:
+			e.cfg.CallerKey = name
+		case KeyFunction:
+			e.cfg.FunctionKey = name
+		case KeyStack:
+			e.cfg.StacktraceKey = name
+		}
+	}
+
+	if c.TimeFormat != "" {
+		timeFormat = c.TimeFormat
+	}
+	e.cfg.EncodeTime = timeEncoder(timeFormat)
+
+	return e
+}
+
+func (e encoding) encoder(format string) zapcore.Encoder {
+	var enc zapcore.Encoder
+	if strings.EqualFold(format, "console") {
+		enc = zapcore.NewConsoleEncoder(e.cfg)
+	} else {
+		enc = zapcore.NewJSONEncoder(e.cfg)
+	}
+
+	if e.extra == nil {
+		return enc
+	}
+	return &extraFieldsEncoder{Encoder: enc, extra: e.extra}
+}
+
+func timeEncoder(format string) zapcore.TimeEncoder {
+	layout := func(layout string) zapcore.TimeEncoder {
+		return func(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+			enc.AppendString(t.UTC().Format(layout))
+		}
+	}
+
+	switch format {
+	case TimeRFC3339:
+		return layout(time.RFC3339)
+	case TimeRFC3339Milli:
+		return layout(timeLayout)
+	case TimeRFC3339Nano:
+		return layout(time.RFC3339Nano)
+	case TimeEpoch:
+		return zapcore.EpochTimeEncoder
+	case TimeEpochMillis:
+		return zapcore.EpochMillisTimeEncoder
+	case TimeEpochNanos:
+		return zapcore.EpochNanosTimeEncoder
+	}
+	return nil
+}
+
+func gcpSeverity(l zapcore.Level) string {
+	switch {
+	case l >= zapcore.FatalLevel:
+		return "EMERGENCY"
+	case l >= zapcore.PanicLevel:
+		return "ALERT"
+	case l >= zapcore.DPanicLevel:
+		return "CRITICAL"
+	case l >= zapcore.ErrorLevel:
+		return "ERROR"
+	case l >= zapcore.WarnLevel:
+		return "WARNING"
+	case l >= zapcore.InfoLevel:
+		return "INFO"
+	default:
+		return "DEBUG"
+	}
+}
+
+// ecsFields returns the ECS version and the caller, whose file and line are
+// separate fields in ECS.
+func ecsFields(ent zapcore.Entry) []zapcore.Field {
+	fields := []zapcore.Field{zap.String("ecs.version", ecsVersion)}
+	if ent.Caller.Defined {
+		fields = append(fields,
+			zap.String("log.origin.file.name", filepath.Base(ent.Caller.File)),
+			zap.Int("log.origin.file.line", ent.Caller.Line),
+		)
+	}
+	return fields
+}
+
+func gcpSourceLocation(ent zapcore.Entry) []zapcore.Field {
+	if !ent.Caller.Defined {
+		return nil
+	}
+	return []zapcore.Field{zap.Object("logging.googleapis.com/sourceLocation",
+		zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+			enc.AddString("file", ent.Caller.File)
+			enc.AddString("line", fmt.Sprint(ent.Caller.Line))
+			enc.AddString("function", ent.Caller.Function)
+			return nil
+		}),
+	)}
+}
+
+func otelSeverity(l zapcore.Level) int {
+	switch {
+	case l >= zapcore.DPanicLevel:
+		return 21
+	case l >= zapcore.ErrorLevel:
+		return 17
+	case l >= zapcore.WarnLevel:
+		return 13
+	case l >= zapcore.InfoLevel:
+		return 9
+	case l == zapcore.DebugLevel:
+		return 5
+	default:
+		return 1
+	}
+}
+
+// extraFieldsEncoder adds the fields of a preset to every entry.
+type extraFieldsEncoder struct {
+	zapcore.Encoder
+	extra func(ent zapcore.Entry) []zapcore.Field
+}
+
+func (e *extraFieldsEncoder) Clone() zapcore.Encoder {
+	return &extraFieldsEncoder{Encoder: e.Encoder.Clone(), extra: e.extra}
+}
+
+func (e *extraFieldsEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	return e.Encoder.EncodeEntry(ent, append(e.extra(ent), fields...))
+}
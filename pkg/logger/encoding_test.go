@@ -0,0 +1,113 @@
+package logger_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sangrita-tech/platform-go-pkg/pkg/logger"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func encodedLine(t *testing.T, enc logger.EncodingConfig, log func(l *zap.Logger)) map[string]any {
+	t.Helper()
+
+	l, capture, err := logger.NewInMemory(&logger.Config{Level: "info", Format: "json", Encoding: enc})
+	require.NoError(t, err)
+	log(l)
+
+	var m map[string]any
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimSpace(capture.Text())), &m))
+	return m
+}
+
+func Test_Encoding_ECS_UsesECSKeys(t *testing.T) {
+	m := encodedLine(t, logger.EncodingConfig{Preset: logger.PresetECS}, func(l *zap.Logger) {
+		l.Named("api").Warn("slow")
+	})
+
+	require.Equal(t, "warn", m["log.level"])
+	require.Equal(t, "slow", m["message"])
+	require.Equal(t, "api", m["log.logger"])
+	require.Equal(t, "1.6.0", m["ecs.version"])
+	require.Contains(t, m, "@timestamp")
+	require.Equal(t, "encoding_test.go", m["log.origin.file.name"])
+	require.IsType(t, float64(0), m["log.origin.file.line"])
+	require.Contains(t, m["log.origin.function"], "Test_Encoding_ECS_UsesECSKeys")
+}
+
+func Test_Encoding_GCP_UsesSeverityAndSourceLocation(t *testing.T) {
+	m := encodedLine(t, logger.EncodingConfig{Preset: logger.PresetGCP}, func(l *zap.Logger) {
+		l.Warn("slow")
+	})
+
+	require.Equal(t, "WARNING", m["severity"])
+	require.Equal(t, "slow", m["message"])
+	require.NotContains(t, m, "caller")
+
+	loc, ok := m["logging.googleapis.com/sourceLocation"].(map[string]any)
+	require.True(t, ok)
+	require.Contains(t, loc["file"], "encoding_test.go")
+	require.NotEmpty(t, loc["line"])
+	require.Contains(t, loc["function"], "Test_Encoding_GCP")
+
+	_, err := time.Parse(time.RFC3339Nano, m["time"].(string))
+	require.NoError(t, err)
+}
+
+func Test_Encoding_OTel_AddsSeverityNumber(t *testing.T) {
+	m := encodedLine(t, logger.EncodingConfig{Preset: logger.PresetOTel}, func(l *zap.Logger) {
+		l.Error("failed")
+	})
+
+	require.Equal(t, "ERROR", m["severity_text"])
+	require.Equal(t, float64(17), m["severity_number"])
+	require.Equal(t, "failed", m["body"])
+	require.IsType(t, float64(0), m["timestamp"])
+}
+
+func Test_Encoding_KeysAndTimeFormat_OverridePreset(t *testing.T) {
+	enc := logger.EncodingConfig{
+		Preset:     logger.PresetDefault,
+		Keys:       map[string]string{logger.KeyMessage: "message", logger.KeyCaller: ""},
+		TimeFormat: logger.TimeEpochMillis,
+	}
+	before := time.Now().UnixMilli()
+	m := encodedLine(t, enc, func(l *zap.Logger) {
+		l.Info("hello")
+	})
+
+	require.Equal(t, "hello", m["message"])
+	require.NotContains(t, m, "msg")
+	require.NotContains(t, m, "caller")
+	require.GreaterOrEqual(t, m["time"], float64(before))
+}
+
+func Test_Encoding_Capture_KeepsDefaultKeys(t *testing.T) {
+	l, capture, err := logger.NewInMemory(&logger.Config{
+		Level:    "info",
+		Format:   "json",
+		Encoding: logger.EncodingConfig{Preset: logger.PresetGCP},
+	})
+	require.NoError(t, err)
+
+	l.Info("hello")
+
+	entries := capture.Entries()
+	require.Len(t, entries, 1)
+	require.Equal(t, "hello", entries[0].Message)
+	require.Empty(t, entries[0].Fields)
+}
+
+func Test_Encoding_Invalid_ReturnsError(t *testing.T) {
+	for _, enc := range []logger.EncodingConfig{
+		{Preset: "splunk"},
+		{Keys: map[string]string{"msg": "message"}},
+		{TimeFormat: "unix"},
+	} {
+		_, _, err := logger.NewInMemory(&logger.Config{Level: "info", Format: "json", Encoding: enc})
+		require.ErrorContains(t, err, "encoding -> ")
+	}
+}
@@ -0,0 +1,355 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/url"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+const maxStackFrames = 64
+
+// Err logs err under the error key as its chain of causes, see ErrorChain.
+func Err(err error) zap.Field {
+	return ErrorChain("error", err)
+}
+
+// ErrorChain logs err under key as an object with its full message, its
+// causes from the outermost wrap inwards and, if one was captured, the stack
+// of the innermost error that has one. Each cause has its type, the part of
+// the message it added and the fields of known error types, see
+// RegisterErrorType. Errors implementing zapcore.ObjectMarshaler add their
+// own fields.
+func ErrorChain(key string, err error) zap.Field {
+	if err == nil {
+		return zap.Skip()
+	}
+	return zap.Object(key, errorChain{err: err, depth: -1})
+}
+
+var (
+	errorTypesMu sync.RWMutex
+	errorTypes   []func(err error, enc zapcore.ObjectEncoder) bool
+)
+
+// RegisterErrorType adds the fields fn encodes to the causes of type T
+// logged by ErrorChain. T may be an interface.
+func RegisterErrorType[T any](fn func(err T, enc zapcore.ObjectEncoder)) {
+	errorTypesMu.Lock()
+	defer errorTypesMu.Unlock()
+
+	errorTypes = append(errorTypes, func(err error, enc zapcore.ObjectEncoder) bool {
+		t, ok := any(err).(T)
+		if ok {
+			fn(t, enc)
+		}
+		return ok
+	})
+}
+
+func init() {
+	RegisterErrorType(func(err *fs.PathError, enc zapcore.ObjectEncoder) {
+		enc.AddString("op", err.Op)
+		enc.AddString("path", err.Path)
+	})
+	RegisterErrorType(func(err *url.Error, enc zapcore.ObjectEncoder) {
+		enc.AddString("op", err.Op)
+		enc.AddString("url", err.URL)
+	})
+	RegisterErrorType(func(err *net.OpError, enc zapcore.ObjectEncoder) {
+		enc.AddString("op", err.Op)
+		enc.AddString("net", err.Net)
+		if err.Addr != nil {
+			enc.AddString("addr", err.Addr.String())
+		}
+	})
+	RegisterErrorType(func(err apierrors.APIStatus, enc zapcore.ObjectEncoder) {
+		s := err.Status()
+		enc.AddString("reason", string(s.Reason))
+		enc.AddInt32("code", s.Code)
+		if s.Details != nil {
+			enc.AddString("kind", s.Details.Kind)
+			enc.AddString("name", s.Details.Name)
+		}
+	})
+}
+
+// WithStack returns err with the stack of its caller, unless err already
+// carries one.
+func WithStack(err error) error {
+	if err == nil || stackOf(err) != nil {
+		return err
+	}
+	return &stackError{err: err, pcs: callers()}
+}
+
+// Errorf is fmt.Errorf that captures the stack of its caller unless a
+// wrapped error already carries one.
+func Errorf(format string, args ...any) error {
+	err := fmt.Errorf(format, args...)
+	if stackOf(err) != nil {
+		return err
+	}
+	return &stackError{err: err, pcs: callers()}
+}
+
+// stackError carries the stack captured when an error was wrapped. It adds
+// nothing to the message and is left out of the logged causes.
+type stackError struct {
+	err error
+	pcs []uintptr
+}
+
+func (e *stackError) Error() string { return e.err.Error() }
+
+func (e *stackError) Unwrap() error { return e.err }
+
+func callers() []uintptr {
+	pcs := make([]uintptr, maxStackFrames)
+	return pcs[:runtime.Callers(3, pcs)]
+}
+
+// stackOf returns the innermost stack in the chain of err, from stackError
+// or any error with a StackTrace method returning a slice of program
+// counters, like github.com/pkg/errors.
+func stackOf(err error) []uintptr {
+	var pcs []uintptr
+	walkErrors(err, func(e error) {
+		if s := ownStack(e); s != nil {
+			pcs = s
+		}
+	})
+	return pcs
+}
+
+func ownStack(err error) []uintptr {
+	if se, ok := err.(*stackError); ok {
+		return se.pcs
+	}
+
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+		return nil
+	}
+	out := m.Type().Out(0)
+	if out.Kind() != reflect.Slice || out.Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+
+	v := m.Call(nil)[0]
+	pcs := make([]uintptr, v.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(v.Index(i).Uint())
+	}
+	return pcs
+}
+
+// walkErrors calls fn for err and every error it wraps, depth first.
+func walkErrors(err error, fn func(error)) {
+	for err != nil {
+		fn(err)
+		switch u := err.(type) {
+		case interface{ Unwrap() []error }:
+			for _, e := range u.Unwrap() {
+				walkErrors(e, fn)
+			}
+			return
+		default:
+			err = errors.Unwrap(err)
+		}
+	}
+}
+
+// formatStack renders up to depth frames, all if depth is negative, the way
+// zap renders entry stacks.
+func formatStack(pcs []uintptr, depth int) string {
+	var b strings.Builder
+	frames := runtime.CallersFrames(pcs)
+	for n := 0; depth < 0 || n < depth; n++ {
+		f, more := frames.Next()
+		if f.Function == "" && !more {
+			break
+		}
+		if n > 0 {
+			b.WriteByte('\n')
+		}
+		fmt.Fprintf(&b, "%s\n\t%s:%d", f.Function, f.File, f.Line)
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
+
+type errorChain struct {
+	err   error
+	depth int
+}
+
+func (c errorChain) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("message", c.err.Error())
+
+	var causes []error
+	walkErrors(c.err, func(e error) {
+		if _, ok := e.(*stackError); !ok {
+			causes = append(causes, e)
+		}
+	})
+	if err := enc.AddArray("causes", errorCauses(causes)); err != nil {
+		return err
+	}
+
+	if pcs := stackOf(c.err); pcs != nil && c.depth != 0 {
+		enc.AddString("stack", formatStack(pcs, c.depth))
+	}
+	return nil
+}
+
+type errorCauses []error
+
+func (cs errorCauses) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for _, err := range cs {
+		if err := enc.AppendObject(errorCause{err}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type errorCause struct {
+	err error
+}
+
+func (c errorCause) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("type", fmt.Sprintf("%T", c.err))
+	if msg := ownMessage(c.err); msg != "" {
+		enc.AddString("message", msg)
+	}
+
+	errorTypesMu.RLock()
+	for _, fn := range errorTypes {
+		fn(c.err, enc)
+	}
+	errorTypesMu.RUnlock()
+
+	if m, ok := c.err.(zapcore.ObjectMarshaler); ok {
+		return m.MarshalLogObject(enc)
+	}
+	return nil
+}
+
+// ownMessage is the part of the message of err added on top of the error it
+// wraps, so "config -> failed to read -> open x" wrapping "open x" gives
+// "config -> failed to read". Errors wrapping several errors add nothing.
+func ownMessage(err error) string {
+	msg := err.Error()
+	if _, ok := err.(interface{ Unwrap() []error }); ok {
+		return ""
+	}
+
+	inner := errors.Unwrap(err)
+	for inner != nil {
+		if _, ok := inner.(*stackError); !ok {
+			break
+		}
+		inner = errors.Unwrap(inner)
+	}
+	if inner == nil {
+		return msg
+	}
+
+	own, ok := strings.CutSuffix(msg, inner.Error())
+	if !ok {
+		return msg
+	}
+	own = strings.TrimRight(own, " ")
+	for _, sep := range []string{"->", ":"} {
+		own = strings.TrimSuffix(own, sep)
+	}
+	return strings.TrimRight(own, " ")
+}
+
+// stackDepthEncoder limits the stack of entries and of the error chains
+// logged with them to the depth configured for their level.
+type stackDepthEncoder struct {
+	zapcore.Encoder
+	depth func(zapcore.Level) int
+}
+
+func withStackDepth(enc zapcore.Encoder, cfg map[string]int) zapcore.Encoder {
+	if len(cfg) == 0 {
+		return enc
+	}
+
+	type limit struct {
+		level zapcore.Level
+		depth int
+	}
+	limits := make([]limit, 0, len(cfg))
+	for name, d := range cfg {
+		limits = append(limits, limit{parseLevel(name), d})
+	}
+
+	// The limit of the highest configured level at or below the entry's
+	// applies; entries below every configured level are not limited.
+	depth := func(lvl zapcore.Level) int {
+		best, found := limit{}, false
+		for _, l := range limits {
+			if l.level <= lvl && (!found || l.level > best.level) {
+				best, found = l, true
+			}
+		}
+		if !found {
+			return -1
+		}
+		return best.depth
+	}
+
+	return &stackDepthEncoder{Encoder: enc, depth: depth}
+}
+
+func (e *stackDepthEncoder) Clone() zapcore.Encoder {
+	return &stackDepthEncoder{Encoder: e.Encoder.Clone(), depth: e.depth}
+}
+
+func (e *stackDepthEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	depth := e.depth(ent.Level)
+	if depth < 0 {
+		return e.Encoder.EncodeEntry(ent, fields)
+	}
+
+	ent.Stack = truncateStack(ent.Stack, depth)
+
+	limited := make([]zapcore.Field, len(fields))
+	for i, f := range fields {
+		if ec, ok := f.Interface.(errorChain); ok && f.Type == zapcore.ObjectMarshalerType {
+			ec.depth = depth
+			f.Interface = ec
+		}
+		limited[i] = f
+	}
+	return e.Encoder.EncodeEntry(ent, limited)
+}
+
+// truncateStack keeps the first depth frames of a zap stack, two lines per
+// frame.
+func truncateStack(stack string, depth int) string {
+	if depth == 0 {
+		return ""
+	}
+	lines := strings.SplitAfterN(stack, "\n", 2*depth+1)
+	if len(lines) <= 2*depth {
+		return stack
+	}
+	return strings.TrimSuffix(strings.Join(lines[:2*depth], ""), "\n")
+}
@@ -0,0 +1,121 @@
+package logger_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/sangrita-tech/platform-go-pkg/pkg/logger"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+type quotaError struct {
+	limit int
+}
+
+func (e *quotaError) Error() string { return fmt.Sprintf("quota %d exceeded", e.limit) }
+
+func errorField(t *testing.T, cfg *logger.Config, log func(l *zap.Logger)) map[string]any {
+	t.Helper()
+
+	l, capture, err := logger.NewInMemory(cfg)
+	require.NoError(t, err)
+	log(l)
+
+	entries := capture.Entries()
+	require.Len(t, entries, 1)
+	field, ok := entries[0].Fields["error"].(map[string]any)
+	require.True(t, ok)
+	return field
+}
+
+func Test_Err_WrappedChain_LogsCausesWithFields(t *testing.T) {
+	_, openErr := os.Open("/does/not/exist")
+	err := fmt.Errorf("config -> failed to read -> %w", openErr)
+
+	field := errorField(t, &logger.Config{Level: "info", Format: "json"}, func(l *zap.Logger) {
+		l.Error("load", logger.Err(err))
+	})
+
+	require.Equal(t, err.Error(), field["message"])
+	causes := field["causes"].([]any)
+	require.Len(t, causes, 3)
+
+	require.Equal(t, "*fmt.wrapError", causes[0].(map[string]any)["type"])
+	require.Equal(t, "config -> failed to read", causes[0].(map[string]any)["message"])
+
+	pathErr := causes[1].(map[string]any)
+	require.Equal(t, "*fs.PathError", pathErr["type"])
+	require.Equal(t, "open", pathErr["op"])
+	require.Equal(t, "/does/not/exist", pathErr["path"])
+	require.Equal(t, "open /does/not/exist", pathErr["message"])
+
+	require.Equal(t, "syscall.Errno", causes[2].(map[string]any)["type"])
+	require.NotContains(t, field, "stack")
+}
+
+func Test_Err_KnownAndRegisteredTypes_AddFields(t *testing.T) {
+	logger.RegisterErrorType(func(err *quotaError, enc zapcore.ObjectEncoder) {
+		enc.AddInt("limit", err.limit)
+	})
+
+	notFound := apierrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, "settings")
+	err := errors.Join(&quotaError{limit: 5}, notFound)
+
+	field := errorField(t, &logger.Config{Level: "info", Format: "json"}, func(l *zap.Logger) {
+		l.Error("sync", logger.Err(err))
+	})
+
+	causes := field["causes"].([]any)
+	require.Len(t, causes, 3)
+	require.NotContains(t, causes[0], "message")
+	require.Equal(t, float64(5), causes[1].(map[string]any)["limit"])
+	require.Equal(t, "NotFound", causes[2].(map[string]any)["reason"])
+	require.Equal(t, float64(404), causes[2].(map[string]any)["code"])
+	require.Equal(t, "settings", causes[2].(map[string]any)["name"])
+}
+
+func Test_Errorf_CapturesStackAtWrapTime(t *testing.T) {
+	inner := logger.WithStack(errors.New("refused"))
+	err := logger.Errorf("client -> failed to dial -> %w", inner)
+
+	field := errorField(t, &logger.Config{Level: "info", Format: "json"}, func(l *zap.Logger) {
+		l.Info("dial", logger.Err(err))
+	})
+
+	require.Contains(t, field["stack"], "Test_Errorf_CapturesStackAtWrapTime")
+	require.Equal(t, "client -> failed to dial", field["causes"].([]any)[0].(map[string]any)["message"])
+	require.Len(t, field["causes"], 2)
+}
+
+func Test_StackDepth_LimitsStacksPerLevel(t *testing.T) {
+	cfg := &logger.Config{Level: "info", Format: "json", StackDepth: map[string]int{"info": 0, "error": 1}}
+	err := logger.WithStack(errors.New("refused"))
+
+	field := errorField(t, cfg, func(l *zap.Logger) {
+		l.Info("dial", logger.Err(err))
+	})
+	require.NotContains(t, field, "stack")
+
+	l, capture, newErr := logger.NewInMemory(cfg)
+	require.NoError(t, newErr)
+	l.Error("dial", logger.Err(err))
+
+	e := capture.Entries()[0]
+	require.Equal(t, 1, strings.Count(e.Stack, "\n\t"))
+	require.Equal(t, 1, strings.Count(e.Fields["error"].(map[string]any)["stack"].(string), "\n\t"))
+}
+
+func Test_StackDepth_Invalid_ReturnsError(t *testing.T) {
+	_, _, err := logger.NewInMemory(&logger.Config{Level: "info", Format: "json", StackDepth: map[string]int{"loud": 1}})
+	require.ErrorContains(t, err, "stack depth -> ")
+
+	_, _, err = logger.NewInMemory(&logger.Config{Level: "info", Format: "json", StackDepth: map[string]int{"warn": -1}})
+	require.ErrorContains(t, err, "stack depth -> ")
+}
@@ -0,0 +1,407 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Levels are the level controls of a logger built by New: its root level
+// and the levels of its named loggers. Every logger New builds has its own,
+// returned by LevelsOf.
+type Levels struct {
+	root *levelControl
+	base *zap.Logger
+
+	mu         sync.Mutex
+	components map[string]*levelControl
+}
+
+// newLevels returns the levels of cfg for base, a logger whose core lets
+// every entry through.
+func newLevels(cfg *Config, base *zap.Logger) *Levels {
+	lv := &Levels{base: base, components: make(map[string]*levelControl)}
+	lv.root = newLevelControl(nil, lv.changed)
+
+	lvl := parseLevel(cfg.Level)
+	lv.root.configure(&lvl)
+	for name, level := range cfg.Levels {
+		lvl := parseLevel(level)
+		lv.component(name).configure(&lvl)
+	}
+	return lv
+}
+
+// fallbackLevels stand in for the levels of L before New is called.
+var fallbackLevels = newLevels(&Config{}, nil)
+
+// LevelsOf returns the levels of l, a logger built by New or derived from
+// one, or nil if it has none.
+func LevelsOf(l *zap.Logger) *Levels {
+	if c, ok := innerCore[*levelCore](l.Core()); ok {
+		return c.levels
+	}
+	return nil
+}
+
+// currentLevels returns the levels of L.
+func currentLevels() *Levels {
+	if lv := LevelsOf(L()); lv != nil {
+		return lv
+	}
+	return fallbackLevels
+}
+
+// levelControl is an AtomicLevel with a configured base level it can be
+// temporarily moved away from. A control with a parent follows the parent's
+// level while inherit is set.
+type levelControl struct {
+	level   zap.AtomicLevel
+	parent  *levelControl
+	inherit atomic.Bool
+	changed func()
+
+	mu          sync.Mutex
+	base        zapcore.Level
+	baseInherit bool
+	until       time.Time
+	timer       *time.Timer
+}
+
+func newLevelControl(parent *levelControl, changed func()) *levelControl {
+	c := &levelControl{level: zap.NewAtomicLevel(), parent: parent, changed: changed, base: zapcore.InfoLevel}
+	if parent != nil {
+		c.baseInherit = true
+		c.inherit.Store(true)
+	}
+	return c
+}
+
+func (c *levelControl) Enabled(lvl zapcore.Level) bool {
+	if c.inherit.Load() {
+		return c.parent.Enabled(lvl)
+	}
+	return c.level.Enabled(lvl)
+}
+
+func (c *levelControl) Level() zapcore.Level {
+	if c.inherit.Load() {
+		return c.parent.Level()
+	}
+	return c.level.Level()
+}
+
+// set changes the level. With a positive ttl the change reverts to the base
+// level after ttl, otherwise it becomes the new base level.
+func (c *levelControl) set(lvl zapcore.Level, ttl time.Duration) {
+	defer c.changed()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stopTimer()
+	c.level.SetLevel(lvl)
+	c.inherit.Store(false)
+
+	if ttl <= 0 {
+		c.base = lvl
+		c.baseInherit = false
+		return
+	}
+
+	c.until = time.Now().Add(ttl)
+	var timer *time.Timer
+	timer = time.AfterFunc(ttl, func() {
+		defer c.changed()
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.timer == timer {
+			c.timer = nil
+			c.until = time.Time{}
+			c.restoreBase()
+		}
+	})
+	c.timer = timer
+}
+
+// configure sets the base level, or makes the control follow its parent
+// when lvl is nil, and drops any override.
+func (c *levelControl) configure(lvl *zapcore.Level) {
+	defer c.changed()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stopTimer()
+	c.baseInherit = lvl == nil && c.parent != nil
+	if lvl != nil {
+		c.base = *lvl
+	}
+	c.restoreBase()
+}
+
+// toggleDebug switches to debug until it is toggled back or reset.
+func (c *levelControl) toggleDebug() {
+	defer c.changed()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stopTimer()
+	if c.Level() == zapcore.DebugLevel {
+		c.restoreBase()
+		return
+	}
+	c.level.SetLevel(zapcore.DebugLevel)
+	c.inherit.Store(false)
+}
+
+// reset drops a temporary override.
+func (c *levelControl) reset() {
+	defer c.changed()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stopTimer()
+	c.restoreBase()
+}
+
+func (c *levelControl) restoreBase() {
+	c.level.SetLevel(c.base)
+	c.inherit.Store(c.baseInherit)
+}
+
+func (c *levelControl) stopTimer() {
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+	c.until = time.Time{}
+}
+
+type levelState struct {
+	Level   string                `json:"level"`
+	Base    string                `json:"base"`
+	Until   *time.Time            `json:"until,omitempty"`
+	Loggers map[string]levelState `json:"loggers,omitempty"`
+}
+
+func (c *levelControl) state() levelState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s := levelState{Level: levelName(c.Level()), Base: levelName(c.base)}
+	if c.baseInherit {
+		s.Base = "inherit"
+	}
+	if !c.until.IsZero() {
+		until := c.until.UTC()
+		s.Until = &until
+	}
+	return s
+}
+
+// changed is called by the level controls of lv after they change. Global
+// klog writes to the logger New built last, so only its levels set klog's
+// verbosity.
+func (lv *Levels) changed() {
+	if g := global.Load(); g != nil && LevelsOf(g) == lv {
+		syncKlogVerbosity(lv)
+	}
+}
+
+// component returns the level control of the named logger name, creating
+// one that follows the root level if needed.
+func (lv *Levels) component(name string) *levelControl {
+	lv.mu.Lock()
+	defer lv.mu.Unlock()
+
+	c, ok := lv.components[name]
+	if !ok {
+		c = newLevelControl(lv.root, lv.changed)
+		lv.components[name] = c
+	}
+	return c
+}
+
+func (lv *Levels) control(name string) *levelControl {
+	if name == "" {
+		return lv.root
+	}
+	return lv.component(name)
+}
+
+// lookup is control without creating the level control of an unknown
+// named logger.
+func (lv *Levels) lookup(name string) (*levelControl, bool) {
+	if name == "" {
+		return lv.root, true
+	}
+
+	lv.mu.Lock()
+	defer lv.mu.Unlock()
+
+	c, ok := lv.components[name]
+	return c, ok
+}
+
+type levelRequest struct {
+	Logger string `json:"logger"`
+	Level  string `json:"level"`
+	TTL    string `json:"ttl"`
+}
+
+type levelHandler struct {
+	levels func() *Levels
+}
+
+func (h levelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("logger")
+	lv := h.levels()
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		var req levelRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("logger -> failed to decode request -> %v", err), http.StatusBadRequest)
+			return
+		}
+		if req.Logger != "" {
+			name = req.Logger
+		}
+
+		lvl, ok := lookupLevel(req.Level)
+		if !ok {
+			http.Error(w, fmt.Sprintf("logger -> unknown level %q", req.Level), http.StatusBadRequest)
+			return
+		}
+
+		var ttl time.Duration
+		if req.TTL != "" {
+			var err error
+			if ttl, err = time.ParseDuration(req.TTL); err != nil || ttl <= 0 {
+				http.Error(w, fmt.Sprintf("logger -> invalid ttl %q", req.TTL), http.StatusBadRequest)
+				return
+			}
+		}
+
+		lv.control(name).set(lvl, ttl)
+	case http.MethodDelete:
+		c, ok := lv.lookup(name)
+		if !ok {
+			http.Error(w, fmt.Sprintf("logger -> unknown logger %q", name), http.StatusNotFound)
+			return
+		}
+		c.reset()
+	default:
+		w.Header().Set("Allow", "GET, PUT, POST, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var s levelState
+	if name != "" {
+		c, ok := lv.lookup(name)
+		if !ok {
+			http.Error(w, fmt.Sprintf("logger -> unknown logger %q", name), http.StatusNotFound)
+			return
+		}
+		s = c.state()
+	} else {
+		s = lv.state()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(s)
+}
+
+func (lv *Levels) state() levelState {
+	s := lv.root.state()
+
+	lv.mu.Lock()
+	names := make([]string, 0, len(lv.components))
+	for name := range lv.components {
+		names = append(names, name)
+	}
+	lv.mu.Unlock()
+	sort.Strings(names)
+
+	s.Loggers = make(map[string]levelState, len(names))
+	for _, name := range names {
+		s.Loggers[name] = lv.component(name).state()
+	}
+	return s
+}
+
+// levelName is the name lookupLevel accepts for lvl; verbosity levels below
+// debug are written as vN.
+func levelName(lvl zapcore.Level) string {
+	if lvl < zapcore.DebugLevel {
+		return fmt.Sprintf("v%d", -int(lvl))
+	}
+	return lvl.String()
+}
+
+// Level returns the root level.
+func (lv *Levels) Level() zap.AtomicLevel {
+	return lv.root.level
+}
+
+// SetLevel changes the root level. With a positive ttl the previous level
+// is restored after ttl.
+func (lv *Levels) SetLevel(level string, ttl time.Duration) error {
+	return lv.SetNamedLevel("", level, ttl)
+}
+
+// SetNamedLevel changes the level of the named logger name like SetLevel
+// does for the root logger.
+func (lv *Levels) SetNamedLevel(name, level string, ttl time.Duration) error {
+	lvl, ok := lookupLevel(level)
+	if !ok {
+		return fmt.Errorf("logger -> unknown level %q", level)
+	}
+	lv.control(name).set(lvl, ttl)
+	return nil
+}
+
+// Handler serves the current levels on GET and changes a level on PUT or
+// POST with a body like {"logger": "kube", "level": "debug", "ttl": "10m"};
+// logger and ttl are optional. DELETE drops a temporary override. The logger
+// may also be given as a query parameter; GET and DELETE answer 404 for a
+// named logger without a level control. It can be mounted with
+// healthcheck.Handle.
+func (lv *Levels) Handler() http.Handler {
+	return levelHandler{levels: func() *Levels { return lv }}
+}
+
+// Level returns the root level of L.
+func Level() zap.AtomicLevel {
+	return currentLevels().Level()
+}
+
+// SetLevel changes the root level of L like Levels.SetLevel.
+func SetLevel(level string, ttl time.Duration) error {
+	return currentLevels().SetLevel(level, ttl)
+}
+
+// SetNamedLevel changes the level of the named logger name of L like
+// Levels.SetNamedLevel.
+func SetNamedLevel(name, level string, ttl time.Duration) error {
+	return currentLevels().SetNamedLevel(name, level, ttl)
+}
+
+// LevelHandler is Levels.Handler for the levels of L at the time of each
+// request.
+func LevelHandler() http.Handler {
+	return levelHandler{levels: currentLevels}
+}
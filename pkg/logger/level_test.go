@@ -0,0 +1,196 @@
+package logger_test
+
+import (
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sangrita-tech/platform-go-pkg/pkg/logger"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	klog "k8s.io/klog/v2"
+)
+
+func serveLevel(t *testing.T, method, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	logger.LevelHandler().ServeHTTP(rec, httptest.NewRequest(method, "/loglevel", strings.NewReader(body)))
+	return rec
+}
+
+func Test_LevelHandler_Put_ChangesLevel(t *testing.T) {
+	_, cleanup, err := logger.New(&logger.Config{Level: "info", Format: "json"})
+	require.NoError(t, err)
+	defer cleanup()
+
+	rec := serveLevel(t, http.MethodPut, `{"level": "warn"}`)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), `"level":"warn","base":"warn"`)
+	require.Equal(t, zapcore.WarnLevel, logger.Level().Level())
+}
+
+func Test_LevelHandler_PutWithTTL_RevertsAfterTTL(t *testing.T) {
+	_, cleanup, err := logger.New(&logger.Config{Level: "info", Format: "json"})
+	require.NoError(t, err)
+	defer cleanup()
+
+	rec := serveLevel(t, http.MethodPost, `{"level": "debug", "ttl": "50ms"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), `"until"`)
+	require.Equal(t, zapcore.DebugLevel, logger.Level().Level())
+
+	require.Eventually(t, func() bool {
+		return logger.Level().Level() == zapcore.InfoLevel
+	}, time.Second, 10*time.Millisecond)
+}
+
+func Test_LevelHandler_InvalidRequest_ReturnsBadRequest(t *testing.T) {
+	require.Equal(t, http.StatusBadRequest, serveLevel(t, http.MethodPut, `{"level": "loud"}`).Code)
+	require.Equal(t, http.StatusBadRequest, serveLevel(t, http.MethodPut, `{"level": "info", "ttl": "soon"}`).Code)
+	require.Equal(t, http.StatusMethodNotAllowed, serveLevel(t, http.MethodPatch, "").Code)
+}
+
+func Test_SetLevel_UnknownLevel_ReturnsError(t *testing.T) {
+	require.Error(t, logger.SetLevel("loud", 0))
+	require.NoError(t, logger.SetLevel("error", 0))
+	require.Equal(t, zapcore.ErrorLevel, logger.Level().Level())
+}
+
+func Test_Named_ConfiguredLevel_IndependentOfRoot(t *testing.T) {
+	_, cleanup, err := logger.New(&logger.Config{
+		Level:  "info",
+		Format: "json",
+		Levels: map[string]string{"kube": "warn", "leaderelection": "debug"},
+	})
+	require.NoError(t, err)
+	defer cleanup()
+
+	kube := logger.Named("kube")
+	require.False(t, kube.Core().Enabled(zapcore.InfoLevel))
+	require.True(t, logger.Named("leaderelection").Core().Enabled(zapcore.DebugLevel))
+
+	httpLog := logger.Named("http")
+	require.True(t, httpLog.Core().Enabled(zapcore.InfoLevel))
+	require.NoError(t, logger.SetLevel("error", 0))
+	require.False(t, httpLog.Core().Enabled(zapcore.WarnLevel))
+	require.True(t, kube.Core().Enabled(zapcore.WarnLevel))
+
+	require.NoError(t, logger.SetNamedLevel("kube", "debug", 0))
+	require.True(t, kube.Core().Enabled(zapcore.DebugLevel))
+}
+
+func Test_Named_BeforeNew_WritesThroughGlobalLogger(t *testing.T) {
+	// New sets package state for the rest of the test binary, so the check
+	// runs in a fresh process.
+	if os.Getenv("LOGGER_TEST_BEFORE_NEW") != "1" {
+		cmd := exec.Command(os.Args[0], "-test.run=^Test_Named_BeforeNew_WritesThroughGlobalLogger$")
+		cmd.Env = append(os.Environ(), "LOGGER_TEST_BEFORE_NEW=1")
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+		return
+	}
+
+	core, logs := observer.New(zapcore.InfoLevel)
+	defer zap.ReplaceGlobals(zap.New(core))()
+
+	logger.Named("kube").Info("synced")
+
+	require.Equal(t, 1, logs.Len())
+	require.Equal(t, "kube", logs.All()[0].LoggerName)
+}
+
+func Test_LevelHandler_NamedLogger_SetsOnlyThatLogger(t *testing.T) {
+	_, cleanup, err := logger.New(&logger.Config{Level: "info", Format: "json", Levels: map[string]string{"klog": "v4"}})
+	require.NoError(t, err)
+	defer cleanup()
+	require.True(t, logger.Named("klog").Core().Enabled(zapcore.Level(-4)))
+	require.False(t, logger.Named("klog").Core().Enabled(zapcore.Level(-5)))
+
+	rec := serveLevel(t, http.MethodPut, `{"logger": "kube", "level": "error"}`)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"level": "error", "base": "error"}`, rec.Body.String())
+	require.False(t, logger.Named("kube").Core().Enabled(zapcore.WarnLevel))
+	require.Equal(t, zapcore.InfoLevel, logger.Level().Level())
+
+	rec = serveLevel(t, http.MethodGet, "")
+	require.Contains(t, rec.Body.String(), `"klog":{"level":"v4","base":"v4"}`)
+}
+
+func Test_LevelHandler_GetUnknownLogger_NotFoundWithoutCreatingIt(t *testing.T) {
+	_, cleanup, err := logger.New(&logger.Config{Level: "info", Format: "json"})
+	require.NoError(t, err)
+	defer cleanup()
+
+	rec := httptest.NewRecorder()
+	logger.LevelHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/loglevel?logger=typo", nil))
+	require.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = httptest.NewRecorder()
+	logger.LevelHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/loglevel?logger=typo", nil))
+	require.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = serveLevel(t, http.MethodGet, "")
+	require.NotContains(t, rec.Body.String(), "typo")
+
+	rec = serveLevel(t, http.MethodPut, `{"logger": "typo", "level": "debug"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	rec = httptest.NewRecorder()
+	logger.LevelHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/loglevel?logger=typo", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"level": "debug", "base": "debug"}`, rec.Body.String())
+}
+
+func Test_New_SecondLogger_KeepsLevelsOfFirst(t *testing.T) {
+	first, cleanupFirst, err := logger.New(&logger.Config{Level: "info", Format: "json", Levels: map[string]string{"kube": "warn"}})
+	require.NoError(t, err)
+	defer cleanupFirst()
+	firstLevels := logger.LevelsOf(first)
+	require.NotNil(t, firstLevels)
+
+	second, cleanupSecond, err := logger.New(&logger.Config{Level: "error", Format: "json"})
+	require.NoError(t, err)
+	defer cleanupSecond()
+
+	require.True(t, first.Core().Enabled(zapcore.InfoLevel))
+	require.False(t, firstLevels.Named("kube").Core().Enabled(zapcore.InfoLevel))
+	require.False(t, second.Core().Enabled(zapcore.WarnLevel))
+
+	require.NoError(t, firstLevels.SetLevel("debug", 0))
+	require.True(t, first.With(zap.String("k", "v")).Core().Enabled(zapcore.DebugLevel))
+	require.False(t, second.Core().Enabled(zapcore.WarnLevel))
+
+	require.NoError(t, logger.SetLevel("warn", 0))
+	require.True(t, second.Core().Enabled(zapcore.WarnLevel))
+	require.Equal(t, zapcore.DebugLevel, firstLevels.Level().Level())
+	require.Same(t, logger.LevelsOf(second), logger.LevelsOf(logger.Named("kube")))
+}
+
+func Test_New_KlogLevelNotConfigured_KeepsHostVerbosity(t *testing.T) {
+	fs := flag.NewFlagSet("host", flag.ContinueOnError)
+	klog.InitFlags(fs)
+	require.NoError(t, fs.Set("v", "3"))
+	defer func() { _ = fs.Set("v", "0") }()
+
+	_, cleanup, err := logger.New(&logger.Config{Level: "info", Format: "json"})
+	require.NoError(t, err)
+	defer cleanup()
+	require.NoError(t, logger.SetLevel("error", 0))
+	require.True(t, klog.V(3).Enabled())
+
+	require.NoError(t, logger.SetNamedLevel("klog", "v5", 50*time.Millisecond))
+	require.True(t, klog.V(5).Enabled())
+
+	require.Eventually(t, func() bool {
+		return klog.V(3).Enabled() && !klog.V(4).Enabled()
+	}, time.Second, 10*time.Millisecond)
+}
@@ -3,6 +3,8 @@ package logger
 import (
 	"errors"
 	"fmt"
+	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -12,59 +14,83 @@ import (
 	klog "k8s.io/klog/v2"
 )
 
+const timeLayout = "2006-01-02T15:04:05.000Z07:00"
+
 func New(cfg *Config) (*zap.Logger, func(), error) {
 	if cfg == nil {
 		return nil, nil, errors.New("logger -> config is nil")
 	}
 
-	if err := cfg.validate(); err != nil {
+	if err := cfg.Validate(); err != nil {
 		return nil, nil, fmt.Errorf("logger -> failed to validate config -> %w", err)
 	}
 
-	level := parseLevel(cfg.Level)
-	encCfg := encoderConfigUTC()
-
-	zcfg := zap.Config{
-		Level:            zap.NewAtomicLevelAt(level),
-		Development:      cfg.DevMode,
-		Encoding:         cfg.Format,
-		OutputPaths:      []string{"stdout"},
-		ErrorOutputPaths: []string{"stderr"},
-		EncoderConfig:    encCfg,
-	}
-
-	l, err := zcfg.Build()
+	outs, err := buildOutputs(cfg, newEncoding(&cfg.Encoding))
 	if err != nil {
-		return nil, nil, fmt.Errorf("logger -> failed to build zap logger -> %w", err)
+		return nil, nil, fmt.Errorf("logger -> failed to open outputs -> %w", err)
 	}
 
-	l = l.WithOptions(zap.AddCaller()).With(buildFields(cfg)...)
+	unsampled := zapcore.NewTee(outs.cores...)
+	stats := &dropStats{}
+
+	core := withTrace(wrapSampling(unsampled, &cfg.Sampling, stats), cfg, newRedactor(cfg))
+	b := zap.New(core, options(cfg)...).With(buildFields(cfg)...)
+	stopReporter := stats.startReporter(zap.New(unsampled).With(buildFields(cfg)...), cfg.Sampling.ReportInterval)
+	lv := newLevels(cfg, b)
+	l := withLevel(b, lv, lv.root)
+	global.Store(l)
+	syncKlogVerbosity(lv)
 
 	restoreStdLog := zap.RedirectStdLog(l)
 
-	klog.SetLogger(zapr.NewLogger(l))
+	klog.SetLogger(zapr.NewLogger(lv.Named(klogName)))
 	klog.EnableContextualLogging(true)
 
 	cleanup := func() {
+		stopReporter()
 		restoreStdLog()
 		_ = l.Sync()
+		outs.close()
 	}
 
 	return l, cleanup, nil
 }
 
+// NewInMemory builds a logger like New that writes to the returned Capture
+// instead of the configured outputs. Entries are captured as JSON with the
+// default keys for inspection and as text in the configured Format and
+// Encoding.
 func NewInMemory(cfg *Config) (*zap.Logger, *Capture, error) {
+	if cfg == nil {
+		return nil, nil, errors.New("logger -> config is nil")
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("logger -> failed to validate config -> %w", err)
+	}
+
 	level := parseLevel(cfg.Level)
-	encCfg := encoderConfigUTC()
+	red := newRedactor(cfg)
 
 	cap := &Capture{}
-	encoder := zapcore.NewJSONEncoder(encCfg)
-	core := zapcore.NewCore(encoder, zapcore.AddSync(cap), level)
+	core := zapcore.NewTee(
+		zapcore.NewCore(withStackDepth(withRedaction(zapcore.NewJSONEncoder(encoderConfigUTC()), red), cfg.StackDepth), cap, level),
+		zapcore.NewCore(withStackDepth(withRedaction(newEncoding(&cfg.Encoding).encoder(cfg.Format), red), cfg.StackDepth), cap.textWriter(), level),
+	)
+	core = withTrace(wrapSampling(core, &cfg.Sampling, &dropStats{}), cfg, red)
 
-	l := zap.New(core).WithOptions(zap.AddCaller()).With(buildFields(cfg)...)
+	l := zap.New(core, options(cfg)...).With(buildFields(cfg)...)
 	return l, cap, nil
 }
 
+func options(cfg *Config) []zap.Option {
+	opts := []zap.Option{zap.ErrorOutput(zapcore.Lock(os.Stderr)), zap.AddCaller()}
+	if cfg.DevMode {
+		return append(opts, zap.Development(), zap.AddStacktrace(zapcore.WarnLevel))
+	}
+	return append(opts, zap.AddStacktrace(zapcore.ErrorLevel))
+}
+
 func encoderConfigUTC() zapcore.EncoderConfig {
 	return zapcore.EncoderConfig{
 		TimeKey:       "time",
@@ -76,7 +102,7 @@ func encoderConfigUTC() zapcore.EncoderConfig {
 		EncodeLevel:   zapcore.CapitalLevelEncoder,
 		EncodeCaller:  zapcore.ShortCallerEncoder,
 		EncodeTime: func(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
-			enc.AppendString(t.UTC().Format("2006-01-02T15:04:05.000Z07:00"))
+			enc.AppendString(t.UTC().Format(timeLayout))
 		},
 	}
 }
@@ -90,14 +116,27 @@ func buildFields(cfg *Config) []zap.Field {
 }
 
 func parseLevel(level string) zapcore.Level {
+	lvl, _ := lookupLevel(level)
+	return lvl
+}
+
+func lookupLevel(level string) (zapcore.Level, bool) {
 	switch strings.ToLower(level) {
 	case "debug":
-		return zapcore.DebugLevel
+		return zapcore.DebugLevel, true
+	case "info":
+		return zapcore.InfoLevel, true
 	case "warn", "warning":
-		return zapcore.WarnLevel
+		return zapcore.WarnLevel, true
 	case "error":
-		return zapcore.ErrorLevel
-	default:
-		return zapcore.InfoLevel
+		return zapcore.ErrorLevel, true
+	}
+
+	// vN is klog verbosity N, below debug.
+	if v, ok := strings.CutPrefix(strings.ToLower(level), "v"); ok {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 && n <= 127 {
+			return zapcore.Level(-n), true
+		}
 	}
+	return zapcore.InfoLevel, false
 }
@@ -0,0 +1,153 @@
+package logger
+
+import (
+	"flag"
+	"math"
+	"strconv"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	klog "k8s.io/klog/v2"
+)
+
+// klogName is the named logger global klog output is written to.
+const klogName = "klog"
+
+// minLevel lets every entry through the cores New builds; the level
+// controls decide what is written.
+const minLevel = zapcore.Level(math.MinInt8)
+
+var (
+	klogMu    sync.Mutex
+	klogFlags *flag.FlagSet
+	// klogHostVerbosity is the -v the application had set before the klog
+	// named logger took it over, empty while it has not.
+	klogHostVerbosity string
+)
+
+// levelCore filters the entries of an unfiltered core by a level control.
+type levelCore struct {
+	zapcore.Core
+	enab   zapcore.LevelEnabler
+	levels *Levels
+}
+
+func (c *levelCore) Enabled(lvl zapcore.Level) bool {
+	return c.enab.Enabled(lvl)
+}
+
+func (c *levelCore) Level() zapcore.Level {
+	return zapcore.LevelOf(c.enab)
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields), enab: c.enab, levels: c.levels}
+}
+
+func (c *levelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.enab.Enabled(ent.Level) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
+
+func (c *levelCore) unwrap() zapcore.Core { return c.Core }
+
+func withLevel(l *zap.Logger, lv *Levels, enab zapcore.LevelEnabler) *zap.Logger {
+	return l.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &levelCore{Core: core, enab: enab, levels: lv}
+	}))
+}
+
+// innerCore returns the first core of type T found by unwrapping core.
+func innerCore[T zapcore.Core](core zapcore.Core) (T, bool) {
+	for core != nil {
+		if c, ok := core.(T); ok {
+			return c, true
+		}
+		u, ok := core.(interface{ unwrap() zapcore.Core })
+		if !ok {
+			break
+		}
+		core = u.unwrap()
+	}
+	var zero T
+	return zero, false
+}
+
+// Tee returns a core that writes to core and others, like zapcore.NewTee,
+// and through which LevelsOf and FromContext still find the levels and
+// trace settings of core. Use it to attach cores to a logger built by New.
+func Tee(core zapcore.Core, others ...zapcore.Core) zapcore.Core {
+	return &teeCore{Core: zapcore.NewTee(append([]zapcore.Core{core}, others...)...), primary: core}
+}
+
+type teeCore struct {
+	zapcore.Core
+	primary zapcore.Core
+}
+
+func (c *teeCore) With(fields []zapcore.Field) zapcore.Core {
+	return &teeCore{Core: c.Core.With(fields), primary: c.primary.With(fields)}
+}
+
+func (c *teeCore) unwrap() zapcore.Core { return c.primary }
+
+// Named returns a child of L whose level is set by the name entry of
+// Config.Levels, or follows the root level if there is none, and can be
+// changed with SetNamedLevel or LevelHandler. Levels.Named does the same
+// for any logger built by New.
+//
+// klog verbosity maps to levels below debug: V(n) is written at level vN,
+// so levels: {klog: v4} enables klog.V(4) for client-go. A contextual klog
+// logger, for example klog.NewContext(ctx, zapr.NewLogger(Named("kube"))),
+// follows the level of its named logger the same way.
+func Named(name string) *zap.Logger {
+	return currentLevels().Named(name)
+}
+
+// Named returns the named logger name of the logger lv belongs to. Before
+// New is called it is L().Named(name), without a level of its own.
+func (lv *Levels) Named(name string) *zap.Logger {
+	if lv.base == nil {
+		return L().Named(name)
+	}
+	return withLevel(lv.base, lv, lv.component(name)).Named(name)
+}
+
+// syncKlogVerbosity sets klog's own -v to the verbosity the klog named
+// logger of lv allows, since global klog.V checks it before writing. It
+// only does so while that logger has a level of its own, from Config.Levels
+// or set at runtime; otherwise -v is left to the application and restored
+// once the logger follows the root level again.
+func syncKlogVerbosity(lv *Levels) {
+	lv.mu.Lock()
+	c := lv.components[klogName]
+	lv.mu.Unlock()
+
+	klogMu.Lock()
+	defer klogMu.Unlock()
+
+	if klogFlags == nil {
+		klogFlags = flag.NewFlagSet(klogName, flag.ContinueOnError)
+		klog.InitFlags(klogFlags)
+	}
+
+	if c == nil || c.inherit.Load() {
+		if klogHostVerbosity != "" {
+			_ = klogFlags.Set("v", klogHostVerbosity)
+			klogHostVerbosity = ""
+		}
+		return
+	}
+
+	if klogHostVerbosity == "" {
+		klogHostVerbosity = klogFlags.Lookup("v").Value.String()
+	}
+	v := 0
+	if lvl := c.Level(); lvl < zapcore.InfoLevel {
+		v = -int(lvl)
+	}
+	_ = klogFlags.Set("v", strconv.Itoa(v))
+}
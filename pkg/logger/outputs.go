@@ -0,0 +1,375 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+const (
+	OutputStdout = "stdout"
+	OutputStderr = "stderr"
+	OutputFile   = "file"
+	OutputSyslog = "syslog"
+)
+
+const defaultSyslogAddress = "/dev/log"
+
+// OutputConfig is one destination of log entries. Level and Format default
+// to the ones of Config; Level only raises the level of the output above
+// the root and named logger levels.
+//
+// File outputs rotate once the file reaches MaxSizeMB (default 100) or is
+// older than RotateEvery, keep MaxBackups old files for MaxAge (0 keeps all)
+// and gzip them if Compress is set. Syslog outputs send RFC 3164 messages
+// over Network (unix, unixgram or udp) to Address, /dev/log by default.
+type OutputConfig struct {
+	Type   string `yaml:"type"`
+	Level  string `yaml:"level"`
+	Format string `yaml:"format"`
+
+	Path        string        `yaml:"path"`
+	MaxSizeMB   int           `yaml:"maxSizeMB"`
+	MaxAge      time.Duration `yaml:"maxAge"`
+	MaxBackups  int           `yaml:"maxBackups"`
+	RotateEvery time.Duration `yaml:"rotateEvery"`
+	Compress    bool          `yaml:"compress"`
+
+	Network string `yaml:"network"`
+	Address string `yaml:"address"`
+	Tag     string `yaml:"tag"`
+}
+
+func (c *OutputConfig) Validate() error {
+	if c.Level != "" {
+		if _, ok := lookupLevel(c.Level); !ok {
+			return fmt.Errorf("unknown level %q", c.Level)
+		}
+	}
+
+	switch strings.ToLower(c.Format) {
+	case "", "json", "console":
+	default:
+		return fmt.Errorf("unknown format %q", c.Format)
+	}
+
+	switch c.Type {
+	case OutputStdout, OutputStderr:
+	case OutputFile:
+		if c.Path == "" {
+			return errors.New("file output path must be set")
+		}
+		if c.MaxSizeMB < 0 || c.MaxBackups < 0 || c.MaxAge < 0 || c.RotateEvery < 0 {
+			return errors.New("file output limits must be >= 0")
+		}
+	case OutputSyslog:
+		switch c.Network {
+		case "", "unix", "unixgram", "udp":
+		default:
+			return fmt.Errorf("unknown syslog network %q", c.Network)
+		}
+		if c.Network == "udp" && c.Address == "" {
+			return errors.New("udp syslog address must be set")
+		}
+	default:
+		return fmt.Errorf("unknown output type %q", c.Type)
+	}
+
+	return nil
+}
+
+// AsyncConfig buffers stdout, stderr and file outputs in memory. Buffers are
+// written when full, every FlushInterval and by the cleanup func of New.
+type AsyncConfig struct {
+	Enabled       bool          `yaml:"enabled" env:"ENABLED" env-default:"false"`
+	BufferSize    int           `yaml:"bufferSize" env:"BUFFER_SIZE" env-default:"262144"`
+	FlushInterval time.Duration `yaml:"flushInterval" env:"FLUSH_INTERVAL" env-default:"1s"`
+}
+
+func (c *AsyncConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	if c.BufferSize <= 0 {
+		return errors.New("buffer size must be > 0")
+	}
+
+	if c.FlushInterval <= 0 {
+		return errors.New("flush interval must be > 0")
+	}
+
+	return nil
+}
+
+// outputs are the cores of the configured outputs and the funcs that flush
+// and close them, to be run in order by cleanup.
+type outputs struct {
+	cores   []zapcore.Core
+	closers []func()
+}
+
+func (o *outputs) close() {
+	for _, fn := range o.closers {
+		fn()
+	}
+}
+
+func buildOutputs(cfg *Config, encoding encoding) (*outputs, error) {
+	configs := cfg.Outputs
+	if len(configs) == 0 {
+		configs = []OutputConfig{{Type: OutputStdout}}
+	}
+
+	red := newRedactor(cfg)
+
+	o := &outputs{}
+	for i := range configs {
+		oc := &configs[i]
+
+		format := oc.Format
+		if format == "" {
+			format = cfg.Format
+		}
+		enc := withStackDepth(withRedaction(encoding.encoder(format), red), cfg.StackDepth)
+
+		enab := zapcore.LevelEnabler(minLevel)
+		if oc.Level != "" {
+			enab = parseLevel(oc.Level)
+		}
+
+		if oc.Type == OutputSyslog {
+			w, err := dialSyslog(oc)
+			if err != nil {
+				o.close()
+				return nil, fmt.Errorf("output %d -> %w", i, err)
+			}
+			o.cores = append(o.cores, &syslogCore{LevelEnabler: enab, enc: enc, w: w})
+			o.closers = append(o.closers, func() { _ = w.Close() })
+			continue
+		}
+
+		ws, err := o.openStream(oc)
+		if err != nil {
+			o.close()
+			return nil, fmt.Errorf("output %d -> %w", i, err)
+		}
+
+		if cfg.Async.Enabled {
+			buf := &zapcore.BufferedWriteSyncer{
+				WS:            ws,
+				Size:          cfg.Async.BufferSize,
+				FlushInterval: cfg.Async.FlushInterval,
+			}
+			// Flush before the file behind it is closed.
+			o.closers = append([]func(){func() { _ = buf.Stop() }}, o.closers...)
+			ws = buf
+		}
+
+		o.cores = append(o.cores, zapcore.NewCore(enc, ws, enab))
+	}
+
+	return o, nil
+}
+
+func (o *outputs) openStream(oc *OutputConfig) (zapcore.WriteSyncer, error) {
+	switch oc.Type {
+	case OutputStdout:
+		return zapcore.Lock(os.Stdout), nil
+	case OutputStderr:
+		return zapcore.Lock(os.Stderr), nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(oc.Path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create log dir -> %w", err)
+	}
+
+	maxSize := oc.MaxSizeMB
+	if maxSize == 0 {
+		maxSize = 100
+	}
+	lj := &lumberjack.Logger{
+		Filename:   oc.Path,
+		MaxSize:    maxSize,
+		MaxAge:     int(math.Ceil(oc.MaxAge.Hours() / 24)),
+		MaxBackups: oc.MaxBackups,
+		Compress:   oc.Compress,
+	}
+	o.closers = append(o.closers, func() { _ = lj.Close() })
+
+	if oc.RotateEvery > 0 {
+		stop := rotateEvery(lj, oc.RotateEvery)
+		o.closers = append([]func(){stop}, o.closers...)
+	}
+
+	return zapcore.AddSync(lj), nil
+}
+
+// rotateEvery rotates lj every interval until the returned func is called.
+func rotateEvery(lj *lumberjack.Logger, interval time.Duration) func() {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				_ = lj.Rotate()
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+// syslogWriter sends one RFC 3164 message per write and reconnects once if
+// a write fails.
+type syslogWriter struct {
+	network string
+	address string
+	tag     string
+	host    string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func dialSyslog(oc *OutputConfig) (*syslogWriter, error) {
+	w := &syslogWriter{network: oc.Network, address: oc.Address, tag: oc.Tag}
+	if w.address == "" {
+		w.address = defaultSyslogAddress
+	}
+	if w.tag == "" {
+		w.tag = filepath.Base(os.Args[0])
+	}
+	w.host, _ = os.Hostname()
+
+	if err := w.connect(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *syslogWriter) connect() error {
+	networks := []string{w.network}
+	if w.network == "" || w.network == "unix" {
+		networks = []string{"unixgram", "unix"}
+	}
+
+	var err error
+	for _, network := range networks {
+		var conn net.Conn
+		if conn, err = net.Dial(network, w.address); err == nil {
+			w.conn = conn
+			return nil
+		}
+	}
+	return fmt.Errorf("failed to connect to syslog %s -> %w", w.address, err)
+}
+
+func (w *syslogWriter) write(severity int, msg []byte) error {
+	// Facility user (1).
+	line := fmt.Sprintf("<%d>%s %s %s[%d]: %s\n",
+		8+severity, time.Now().Format(time.Stamp), w.host, w.tag, os.Getpid(), strings.TrimSuffix(string(msg), "\n"))
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn != nil {
+		if _, err := w.conn.Write([]byte(line)); err == nil {
+			return nil
+		}
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+
+	if err := w.connect(); err != nil {
+		return err
+	}
+	_, err := w.conn.Write([]byte(line))
+	return err
+}
+
+func (w *syslogWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+// syslogSeverity maps a zap level to a syslog severity.
+func syslogSeverity(lvl zapcore.Level) int {
+	switch {
+	case lvl >= zapcore.DPanicLevel:
+		return 2
+	case lvl >= zapcore.ErrorLevel:
+		return 3
+	case lvl >= zapcore.WarnLevel:
+		return 4
+	case lvl >= zapcore.InfoLevel:
+		return 6
+	default:
+		return 7
+	}
+}
+
+// syslogCore encodes entries like an io core and writes each of them as one
+// syslog message with the entry's severity.
+type syslogCore struct {
+	zapcore.LevelEnabler
+	enc zapcore.Encoder
+	w   *syslogWriter
+}
+
+func (c *syslogCore) With(fields []zapcore.Field) zapcore.Core {
+	enc := c.enc.Clone()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	return &syslogCore{LevelEnabler: c.LevelEnabler, enc: enc, w: c.w}
+}
+
+func (c *syslogCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *syslogCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	defer buf.Free()
+
+	return c.w.write(syslogSeverity(ent.Level), buf.Bytes())
+}
+
+func (c *syslogCore) Sync() error {
+	return nil
+}
@@ -0,0 +1,100 @@
+package logger_test
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sangrita-tech/platform-go-pkg/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_New_FileOutputs_WritesPerOutputLevelAndFormat(t *testing.T) {
+	dir := t.TempDir()
+	all := filepath.Join(dir, "all.log")
+	errs := filepath.Join(dir, "errors", "errors.log")
+
+	l, cleanup, err := logger.New(&logger.Config{
+		Level:  "debug",
+		Format: "json",
+		Outputs: []logger.OutputConfig{
+			{Type: logger.OutputFile, Path: all, Format: "console"},
+			{Type: logger.OutputFile, Path: errs, Level: "error"},
+		},
+		Async: logger.AsyncConfig{Enabled: true, BufferSize: 1 << 16, FlushInterval: time.Hour},
+	})
+	require.NoError(t, err)
+
+	l.Debug("starting")
+	l.Error("failed")
+
+	raw, _ := os.ReadFile(all)
+	require.Empty(t, raw, "async output must not be written before flush")
+
+	cleanup()
+
+	raw, err = os.ReadFile(all)
+	require.NoError(t, err)
+	require.Contains(t, string(raw), "DEBUG")
+	require.Contains(t, string(raw), "\tstarting")
+
+	raw, err = os.ReadFile(errs)
+	require.NoError(t, err)
+	require.Equal(t, 1, strings.Count(string(raw), "\n"))
+	require.Contains(t, string(raw), `"msg":"failed"`)
+}
+
+func Test_New_RotateEvery_KeepsBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	l, cleanup, err := logger.New(&logger.Config{
+		Level:   "info",
+		Format:  "json",
+		Outputs: []logger.OutputConfig{{Type: logger.OutputFile, Path: path, RotateEvery: 20 * time.Millisecond}},
+	})
+	require.NoError(t, err)
+	defer cleanup()
+
+	require.Eventually(t, func() bool {
+		l.Info("tick")
+		matches, _ := filepath.Glob(filepath.Join(filepath.Dir(path), "app-*.log"))
+		return len(matches) > 0
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func Test_New_SyslogUDP_SendsSeverityAndTag(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	l, cleanup, err := logger.New(&logger.Config{
+		Level:  "info",
+		Format: "json",
+		Outputs: []logger.OutputConfig{
+			{Type: logger.OutputSyslog, Network: "udp", Address: conn.LocalAddr().String(), Tag: "app"},
+		},
+	})
+	require.NoError(t, err)
+	defer cleanup()
+
+	l.Warn("disk almost full")
+
+	buf := make([]byte, 4096)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+
+	msg := string(buf[:n])
+	require.True(t, strings.HasPrefix(msg, "<12>"), msg)
+	require.Contains(t, msg, " app[")
+	require.Contains(t, msg, `"msg":"disk almost full"`)
+}
+
+func Test_Config_InvalidOutput_ReturnsError(t *testing.T) {
+	cfg := &logger.Config{Level: "info", Format: "json", Outputs: []logger.OutputConfig{{Type: logger.OutputFile}}}
+
+	require.ErrorContains(t, cfg.Validate(), "outputs -> 0 -> file output path must be set")
+}
@@ -0,0 +1,615 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	RedactAuto = "auto"
+	RedactOn   = "on"
+	RedactOff  = "off"
+)
+
+// Keys and Patterns used when RedactionConfig leaves them nil, matching its
+// env-default tags.
+var (
+	defaultRedactKeys     = []string{"password", "passwd", "secret", "token", "authorization", "cookie", "api_key", "apikey"}
+	defaultRedactPatterns = []string{"bearer", "jwt", "card"}
+)
+
+// builtinPatterns can be named in RedactionConfig.Patterns instead of a
+// regular expression.
+var builtinPatterns = map[string]*regexp.Regexp{
+	"bearer": regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9\-._~+/]+=*`),
+	"jwt":    regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`),
+	"card":   regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`),
+}
+
+// RedactionConfig masks sensitive values before they are encoded. Fields
+// whose key ends with one of Keys are replaced by Mask, comparing whole
+// segments split on _, -, . and camelCase, case-insensitively: token masks
+// access_token and X-Token but not token_count. In other string values, and
+// in messages, matches of Patterns are replaced by Mask. A pattern is a
+// regular expression or one of the built-in bearer, jwt and card (numbers
+// passing the Luhn check). Mode auto redacts unless DevMode is set.
+type RedactionConfig struct {
+	Mode     string   `yaml:"mode" env:"MODE" env-default:"auto"`
+	Keys     []string `yaml:"keys" env:"KEYS" env-default:"password,passwd,secret,token,authorization,cookie,api_key,apikey"`
+	Patterns []string `yaml:"patterns" env:"PATTERNS" env-separator:";" env-default:"bearer;jwt;card"`
+	Mask     string   `yaml:"mask" env:"MASK" env-default:"[REDACTED]"`
+}
+
+func (c *RedactionConfig) Validate() error {
+	switch c.Mode {
+	case "", RedactAuto, RedactOn, RedactOff:
+	default:
+		return fmt.Errorf("unknown mode %q", c.Mode)
+	}
+
+	for _, p := range c.Patterns {
+		if _, ok := builtinPatterns[p]; ok {
+			continue
+		}
+		if _, err := regexp.Compile(p); err != nil {
+			return fmt.Errorf("invalid pattern %q -> %w", p, err)
+		}
+	}
+
+	return nil
+}
+
+type redactor struct {
+	keys     [][]string
+	patterns []*regexp.Regexp
+	mask     string
+}
+
+// newRedactor returns nil when cfg does not redact.
+func newRedactor(cfg *Config) *redactor {
+	rc := cfg.Redaction
+	switch rc.Mode {
+	case RedactOff:
+		return nil
+	case "", RedactAuto:
+		if cfg.DevMode {
+			return nil
+		}
+	}
+
+	if rc.Keys == nil {
+		rc.Keys = defaultRedactKeys
+	}
+	if rc.Patterns == nil {
+		rc.Patterns = defaultRedactPatterns
+	}
+
+	r := &redactor{mask: rc.Mask}
+	if r.mask == "" {
+		r.mask = "[REDACTED]"
+	}
+	for _, k := range rc.Keys {
+		if segs := keySegments(k); len(segs) > 0 {
+			r.keys = append(r.keys, segs)
+		}
+	}
+	for _, p := range rc.Patterns {
+		if re, ok := builtinPatterns[p]; ok {
+			r.patterns = append(r.patterns, re)
+			continue
+		}
+		r.patterns = append(r.patterns, regexp.MustCompile(p))
+	}
+	return r
+}
+
+// Redactor redacts text written elsewhere than a logger, such as
+// Kubernetes Events, like the encoders of a logger with the same
+// RedactionConfig.
+type Redactor struct {
+	r *redactor
+}
+
+// NewRedactor returns the redactor of a valid cfg. Mode auto redacts, as
+// there is no DevMode to turn it off.
+func NewRedactor(cfg *RedactionConfig) *Redactor {
+	return &Redactor{r: newRedactor(&Config{Redaction: *cfg})}
+}
+
+// Redact returns s with the matches of the patterns replaced by the mask.
+func (r *Redactor) Redact(s string) string {
+	if r == nil || r.r == nil {
+		return s
+	}
+	return r.r.redact(s)
+}
+
+func (r *redactor) sensitive(key string) bool {
+	segs := keySegments(key)
+	for _, k := range r.keys {
+		if len(k) <= len(segs) && slices.Equal(segs[len(segs)-len(k):], k) {
+			return true
+		}
+	}
+	return false
+}
+
+// keySegments splits key on _, -, . and lower-to-upper case changes and
+// lowercases the segments.
+func keySegments(key string) []string {
+	var segs []string
+	start := 0
+	for i, c := range key {
+		switch {
+		case c == '_' || c == '-' || c == '.':
+			if i > start {
+				segs = append(segs, strings.ToLower(key[start:i]))
+			}
+			start = i + 1
+		case unicode.IsUpper(c) && i > start:
+			if prev, _ := utf8.DecodeLastRuneInString(key[:i]); unicode.IsLower(prev) || unicode.IsDigit(prev) {
+				segs = append(segs, strings.ToLower(key[start:i]))
+				start = i
+			}
+		}
+	}
+	if start < len(key) {
+		segs = append(segs, strings.ToLower(key[start:]))
+	}
+	return segs
+}
+
+func (r *redactor) redact(s string) string {
+	for _, re := range r.patterns {
+		if re == builtinPatterns["card"] {
+			s = re.ReplaceAllStringFunc(s, func(m string) string {
+				if luhn(m) {
+					return r.mask
+				}
+				return m
+			})
+			continue
+		}
+		s = re.ReplaceAllString(s, r.mask)
+	}
+	return s
+}
+
+// field returns f with its value masked or redacted.
+func (r *redactor) field(f zapcore.Field) zapcore.Field {
+	if r.sensitive(f.Key) {
+		return zap.String(f.Key, r.mask)
+	}
+
+	switch f.Type {
+	case zapcore.StringType:
+		f.String = r.redact(f.String)
+	case zapcore.ByteStringType:
+		f = zap.String(f.Key, r.redact(string(f.Interface.([]byte))))
+	case zapcore.StringerType:
+		f = zap.String(f.Key, r.redact(fmt.Sprint(f.Interface)))
+	case zapcore.ErrorType:
+		if err, ok := f.Interface.(error); ok {
+			if msg := err.Error(); r.redact(msg) != msg {
+				f = zap.String(f.Key, r.redact(msg))
+			}
+		}
+	case zapcore.ObjectMarshalerType:
+		f.Interface = redactObject{obj: f.Interface.(zapcore.ObjectMarshaler), r: r}
+	case zapcore.ArrayMarshalerType:
+		f.Interface = redactArray{arr: f.Interface.(zapcore.ArrayMarshaler), r: r}
+	case zapcore.ReflectType:
+		f = r.reflected(f.Key, f.Interface)
+	}
+	return f
+}
+
+// maxReflectDepth bounds the walk of reflected values, which may be cyclic.
+const maxReflectDepth = 32
+
+// reflected returns the field of a value logged with zap.Any or
+// zap.Reflect, with the maps, structs and slices in it walked so that their
+// keys and strings are redacted like fields. Values that marshal themselves
+// are left to the encoder.
+func (r *redactor) reflected(key string, value any) zapcore.Field {
+	return r.reflectedValue(key, reflect.ValueOf(value), 0)
+}
+
+func (r *redactor) reflectedValue(key string, v reflect.Value, depth int) zapcore.Field {
+	if r.sensitive(key) {
+		return zap.String(key, r.mask)
+	}
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() || selfMarshaling(v) {
+			break
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() || !v.CanInterface() || selfMarshaling(v) || depth >= maxReflectDepth {
+		return zap.Reflect(key, interfaceOf(v))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return zap.String(key, r.redact(v.String()))
+	case reflect.Map:
+		if v.IsNil() {
+			break
+		}
+		return zap.Object(key, zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+			keys := v.MapKeys()
+			names := make([]string, len(keys))
+			for i, k := range keys {
+				names[i] = fmt.Sprint(k.Interface())
+			}
+			order := make([]int, len(keys))
+			for i := range order {
+				order[i] = i
+			}
+			slices.SortFunc(order, func(a, b int) int { return strings.Compare(names[a], names[b]) })
+			for _, i := range order {
+				r.reflectedValue(names[i], v.MapIndex(keys[i]), depth+1).AddTo(enc)
+			}
+			return nil
+		}))
+	case reflect.Struct:
+		return zap.Object(key, zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+			r.addStructFields(enc, v, depth+1)
+			return nil
+		}))
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && (v.IsNil() || v.Type().Elem().Kind() == reflect.Uint8) {
+			break
+		}
+		return zap.Array(key, zapcore.ArrayMarshalerFunc(func(enc zapcore.ArrayEncoder) error {
+			for i := range v.Len() {
+				f := r.reflectedValue("", v.Index(i), depth+1)
+				if err := appendField(enc, f); err != nil {
+					return err
+				}
+			}
+			return nil
+		}))
+	}
+	return zap.Reflect(key, v.Interface())
+}
+
+// addStructFields adds the fields of struct v to enc, flattening embedded
+// structs like encoding/json.
+func (r *redactor) addStructFields(enc zapcore.ObjectEncoder, v reflect.Value, depth int) {
+	t := v.Type()
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct && sf.Tag.Get("json") == "" {
+			r.addStructFields(enc, v.Field(i), depth)
+			continue
+		}
+		name, omitEmpty, ok := jsonField(sf)
+		if !ok || (omitEmpty && v.Field(i).IsZero()) {
+			continue
+		}
+		r.reflectedValue(name, v.Field(i), depth).AddTo(enc)
+	}
+}
+
+func interfaceOf(v reflect.Value) any {
+	if !v.IsValid() || !v.CanInterface() {
+		return nil
+	}
+	return v.Interface()
+}
+
+// selfMarshaling reports whether v encodes itself, like time.Time.
+func selfMarshaling(v reflect.Value) bool {
+	if !v.CanInterface() {
+		return false
+	}
+	switch v.Interface().(type) {
+	case json.Marshaler, interface{ MarshalText() ([]byte, error) }:
+		return true
+	}
+	return false
+}
+
+// jsonField returns the key a struct field is encoded under by
+// encoding/json, and false if it is not encoded.
+func jsonField(sf reflect.StructField) (name string, omitEmpty, ok bool) {
+	if !sf.IsExported() {
+		return "", false, false
+	}
+	tag := sf.Tag.Get("json")
+	if tag == "-" {
+		return "", false, false
+	}
+	name, opts, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = sf.Name
+	}
+	return name, slices.Contains(strings.Split(opts, ","), "omitempty"), true
+}
+
+// appendField appends the value of a field built by reflectedValue to an
+// array.
+func appendField(enc zapcore.ArrayEncoder, f zapcore.Field) error {
+	switch f.Type {
+	case zapcore.StringType:
+		enc.AppendString(f.String)
+		return nil
+	case zapcore.ObjectMarshalerType:
+		return enc.AppendObject(f.Interface.(zapcore.ObjectMarshaler))
+	case zapcore.ArrayMarshalerType:
+		return enc.AppendArray(f.Interface.(zapcore.ArrayMarshaler))
+	}
+	return enc.AppendReflected(f.Interface)
+}
+
+func luhn(s string) bool {
+	sum, n := 0, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n >= 13 && sum%10 == 0
+}
+
+// redactEncoder redacts fields added with With and the fields and message
+// of every entry before its inner encoder sees them.
+type redactEncoder struct {
+	redactObjectEncoder
+	enc zapcore.Encoder
+}
+
+func withRedaction(enc zapcore.Encoder, r *redactor) zapcore.Encoder {
+	if r == nil {
+		return enc
+	}
+	return newRedactEncoder(enc, r)
+}
+
+func newRedactEncoder(enc zapcore.Encoder, r *redactor) *redactEncoder {
+	return &redactEncoder{redactObjectEncoder: redactObjectEncoder{ObjectEncoder: enc, r: r}, enc: enc}
+}
+
+func (e *redactEncoder) Clone() zapcore.Encoder {
+	return newRedactEncoder(e.enc.Clone(), e.r)
+}
+
+func (e *redactEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	ent.Message = e.r.redact(ent.Message)
+
+	redacted := make([]zapcore.Field, len(fields))
+	for i, f := range fields {
+		redacted[i] = e.r.field(f)
+	}
+	return e.enc.EncodeEntry(ent, redacted)
+}
+
+// redactObjectEncoder redacts the fields added to an encoder, which is the
+// encoder of a core for fields added with With and the encoder nested
+// objects are marshaled into otherwise.
+type redactObjectEncoder struct {
+	zapcore.ObjectEncoder
+	r *redactor
+}
+
+// masked adds the mask under key and reports true if key is sensitive.
+func (e *redactObjectEncoder) masked(key string) bool {
+	if !e.r.sensitive(key) {
+		return false
+	}
+	e.ObjectEncoder.AddString(key, e.r.mask)
+	return true
+}
+
+func (e *redactObjectEncoder) AddString(key, value string) {
+	e.r.field(zap.String(key, value)).AddTo(e.ObjectEncoder)
+}
+
+func (e *redactObjectEncoder) AddByteString(key string, value []byte) {
+	e.r.field(zap.ByteString(key, value)).AddTo(e.ObjectEncoder)
+}
+
+func (e *redactObjectEncoder) AddObject(key string, obj zapcore.ObjectMarshaler) error {
+	return addRedacted(e.ObjectEncoder, e.r, zap.Object(key, obj))
+}
+
+func (e *redactObjectEncoder) AddArray(key string, arr zapcore.ArrayMarshaler) error {
+	return addRedacted(e.ObjectEncoder, e.r, zap.Array(key, arr))
+}
+
+func (e *redactObjectEncoder) AddReflected(key string, value any) error {
+	return addRedacted(e.ObjectEncoder, e.r, zap.Reflect(key, value))
+}
+
+func (e *redactObjectEncoder) AddBinary(key string, value []byte) {
+	if !e.masked(key) {
+		e.ObjectEncoder.AddBinary(key, value)
+	}
+}
+
+func (e *redactObjectEncoder) AddBool(key string, value bool) {
+	if !e.masked(key) {
+		e.ObjectEncoder.AddBool(key, value)
+	}
+}
+
+func (e *redactObjectEncoder) AddComplex128(key string, value complex128) {
+	if !e.masked(key) {
+		e.ObjectEncoder.AddComplex128(key, value)
+	}
+}
+
+func (e *redactObjectEncoder) AddComplex64(key string, value complex64) {
+	if !e.masked(key) {
+		e.ObjectEncoder.AddComplex64(key, value)
+	}
+}
+
+func (e *redactObjectEncoder) AddDuration(key string, value time.Duration) {
+	if !e.masked(key) {
+		e.ObjectEncoder.AddDuration(key, value)
+	}
+}
+
+func (e *redactObjectEncoder) AddFloat64(key string, value float64) {
+	if !e.masked(key) {
+		e.ObjectEncoder.AddFloat64(key, value)
+	}
+}
+
+func (e *redactObjectEncoder) AddFloat32(key string, value float32) {
+	if !e.masked(key) {
+		e.ObjectEncoder.AddFloat32(key, value)
+	}
+}
+
+func (e *redactObjectEncoder) AddInt(key string, value int) {
+	if !e.masked(key) {
+		e.ObjectEncoder.AddInt(key, value)
+	}
+}
+
+func (e *redactObjectEncoder) AddInt64(key string, value int64) {
+	if !e.masked(key) {
+		e.ObjectEncoder.AddInt64(key, value)
+	}
+}
+
+func (e *redactObjectEncoder) AddInt32(key string, value int32) {
+	if !e.masked(key) {
+		e.ObjectEncoder.AddInt32(key, value)
+	}
+}
+
+func (e *redactObjectEncoder) AddInt16(key string, value int16) {
+	if !e.masked(key) {
+		e.ObjectEncoder.AddInt16(key, value)
+	}
+}
+
+func (e *redactObjectEncoder) AddInt8(key string, value int8) {
+	if !e.masked(key) {
+		e.ObjectEncoder.AddInt8(key, value)
+	}
+}
+
+func (e *redactObjectEncoder) AddTime(key string, value time.Time) {
+	if !e.masked(key) {
+		e.ObjectEncoder.AddTime(key, value)
+	}
+}
+
+func (e *redactObjectEncoder) AddUint(key string, value uint) {
+	if !e.masked(key) {
+		e.ObjectEncoder.AddUint(key, value)
+	}
+}
+
+func (e *redactObjectEncoder) AddUint64(key string, value uint64) {
+	if !e.masked(key) {
+		e.ObjectEncoder.AddUint64(key, value)
+	}
+}
+
+func (e *redactObjectEncoder) AddUint32(key string, value uint32) {
+	if !e.masked(key) {
+		e.ObjectEncoder.AddUint32(key, value)
+	}
+}
+
+func (e *redactObjectEncoder) AddUint16(key string, value uint16) {
+	if !e.masked(key) {
+		e.ObjectEncoder.AddUint16(key, value)
+	}
+}
+
+func (e *redactObjectEncoder) AddUint8(key string, value uint8) {
+	if !e.masked(key) {
+		e.ObjectEncoder.AddUint8(key, value)
+	}
+}
+
+func (e *redactObjectEncoder) AddUintptr(key string, value uintptr) {
+	if !e.masked(key) {
+		e.ObjectEncoder.AddUintptr(key, value)
+	}
+}
+
+func addRedacted(enc zapcore.ObjectEncoder, r *redactor, f zapcore.Field) error {
+	f = r.field(f)
+	switch f.Type {
+	case zapcore.ObjectMarshalerType:
+		return enc.AddObject(f.Key, f.Interface.(zapcore.ObjectMarshaler))
+	case zapcore.ArrayMarshalerType:
+		return enc.AddArray(f.Key, f.Interface.(zapcore.ArrayMarshaler))
+	case zapcore.ReflectType:
+		return enc.AddReflected(f.Key, f.Interface)
+	}
+	f.AddTo(enc)
+	return nil
+}
+
+type redactObject struct {
+	obj zapcore.ObjectMarshaler
+	r   *redactor
+}
+
+func (o redactObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	return o.obj.MarshalLogObject(&redactObjectEncoder{ObjectEncoder: enc, r: o.r})
+}
+
+type redactArray struct {
+	arr zapcore.ArrayMarshaler
+	r   *redactor
+}
+
+func (a redactArray) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	return a.arr.MarshalLogArray(&redactArrayEncoder{ArrayEncoder: enc, r: a.r})
+}
+
+type redactArrayEncoder struct {
+	zapcore.ArrayEncoder
+	r *redactor
+}
+
+func (e *redactArrayEncoder) AppendString(value string) {
+	e.ArrayEncoder.AppendString(e.r.redact(value))
+}
+
+func (e *redactArrayEncoder) AppendByteString(value []byte) {
+	e.ArrayEncoder.AppendString(e.r.redact(string(value)))
+}
+
+func (e *redactArrayEncoder) AppendObject(obj zapcore.ObjectMarshaler) error {
+	return e.ArrayEncoder.AppendObject(redactObject{obj: obj, r: e.r})
+}
+
+func (e *redactArrayEncoder) AppendArray(arr zapcore.ArrayMarshaler) error {
+	return e.ArrayEncoder.AppendArray(redactArray{arr: arr, r: e.r})
+}
+
+func (e *redactArrayEncoder) AppendReflected(value any) error {
+	return appendField(e.ArrayEncoder, e.r.reflected("", value))
+}
@@ -0,0 +1,208 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	dropReasonSampled     = "sampled"
+	dropReasonRateLimited = "rate_limited"
+)
+
+var droppedEntries = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "logger_dropped_entries_total",
+	Help: "Log entries dropped by sampling or rate limiting.",
+}, []string{"reason", "level"})
+
+// MetricsCollector returns the counter of dropped log entries, to be
+// registered with the application's prometheus registry.
+func MetricsCollector() prometheus.Collector {
+	return droppedEntries
+}
+
+// dropStats counts dropped entries between two summary logs.
+type dropStats struct {
+	sampled     atomic.Uint64
+	rateLimited atomic.Uint64
+}
+
+func (s *dropStats) drop(reason string, lvl zapcore.Level) {
+	droppedEntries.WithLabelValues(reason, lvl.String()).Inc()
+	if reason == dropReasonSampled {
+		s.sampled.Add(1)
+	} else {
+		s.rateLimited.Add(1)
+	}
+}
+
+// report logs the entries dropped since the last report, if any.
+func (s *dropStats) report(l *zap.Logger) {
+	sampled, rateLimited := s.sampled.Swap(0), s.rateLimited.Swap(0)
+	if sampled == 0 && rateLimited == 0 {
+		return
+	}
+	l.Warn("dropped log entries",
+		zap.Uint64(dropReasonSampled, sampled),
+		zap.Uint64(dropReasonRateLimited, rateLimited),
+	)
+}
+
+// startReporter reports dropped entries every interval until the
+// returned func is called, which writes a last report.
+func (s *dropStats) startReporter(l *zap.Logger, interval time.Duration) func() {
+	if interval <= 0 {
+		return func() { s.report(l) }
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				s.report(l)
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			wg.Wait()
+			s.report(l)
+		})
+	}
+}
+
+// wrapSampling applies the sampler and the per-message rate limit of cfg to
+// core. Error and higher entries bypass both when cfg.ExemptErrors is set.
+func wrapSampling(core zapcore.Core, cfg *SamplingConfig, stats *dropStats) zapcore.Core {
+	if cfg.Initial <= 0 && cfg.RateLimit <= 0 {
+		return core
+	}
+
+	limited := core
+	if cfg.RateLimit > 0 {
+		limited = &rateLimitCore{Core: limited, counts: newRateCounts(cfg.RateLimit, cfg.Tick), stats: stats}
+	}
+	if cfg.Initial > 0 {
+		limited = zapcore.NewSamplerWithOptions(limited, cfg.Tick, cfg.Initial, cfg.Thereafter,
+			zapcore.SamplerHook(func(ent zapcore.Entry, dec zapcore.SamplingDecision) {
+				if dec&zapcore.LogDropped != 0 {
+					stats.drop(dropReasonSampled, ent.Level)
+				}
+			}),
+		)
+	}
+
+	if !cfg.ExemptErrors {
+		return limited
+	}
+	return &splitCore{below: limited, above: core, threshold: zapcore.ErrorLevel}
+}
+
+// splitCore sends entries at or above threshold to above and the others to
+// below.
+type splitCore struct {
+	below     zapcore.Core
+	above     zapcore.Core
+	threshold zapcore.Level
+}
+
+func (c *splitCore) Enabled(lvl zapcore.Level) bool {
+	return c.pick(lvl).Enabled(lvl)
+}
+
+func (c *splitCore) With(fields []zapcore.Field) zapcore.Core {
+	return &splitCore{below: c.below.With(fields), above: c.above.With(fields), threshold: c.threshold}
+}
+
+func (c *splitCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return c.pick(ent.Level).Check(ent, ce)
+}
+
+func (c *splitCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.pick(ent.Level).Write(ent, fields)
+}
+
+func (c *splitCore) Sync() error {
+	return c.below.Sync()
+}
+
+func (c *splitCore) pick(lvl zapcore.Level) zapcore.Core {
+	if lvl >= c.threshold {
+		return c.above
+	}
+	return c.below
+}
+
+// rateCounts counts entries per level and message in the current tick.
+type rateCounts struct {
+	limit int
+	tick  time.Duration
+
+	mu     sync.Mutex
+	start  time.Time
+	counts map[rateKey]int
+}
+
+type rateKey struct {
+	level zapcore.Level
+	msg   string
+}
+
+func newRateCounts(limit int, tick time.Duration) *rateCounts {
+	return &rateCounts{limit: limit, tick: tick, counts: make(map[rateKey]int)}
+}
+
+func (r *rateCounts) allow(ent zapcore.Entry) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if ent.Time.Sub(r.start) >= r.tick || ent.Time.Before(r.start) {
+		r.start = ent.Time
+		clear(r.counts)
+	}
+
+	key := rateKey{level: ent.Level, msg: ent.Message}
+	r.counts[key]++
+	return r.counts[key] <= r.limit
+}
+
+// rateLimitCore drops entries whose message was already written limit times
+// in the current tick.
+type rateLimitCore struct {
+	zapcore.Core
+	counts *rateCounts
+	stats  *dropStats
+}
+
+func (c *rateLimitCore) With(fields []zapcore.Field) zapcore.Core {
+	return &rateLimitCore{Core: c.Core.With(fields), counts: c.counts, stats: c.stats}
+}
+
+func (c *rateLimitCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(ent.Level) {
+		return ce
+	}
+	if !c.counts.allow(ent) {
+		c.stats.drop(dropReasonRateLimited, ent.Level)
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
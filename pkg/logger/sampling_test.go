@@ -0,0 +1,78 @@
+package logger_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sangrita-tech/platform-go-pkg/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func droppedCount(t *testing.T, reason, level string) float64 {
+	t.Helper()
+
+	vec, ok := logger.MetricsCollector().(*prometheus.CounterVec)
+	require.True(t, ok)
+	return testutil.ToFloat64(vec.WithLabelValues(reason, level))
+}
+
+func Test_NewInMemory_Sampling_DropsRepeatedMessages(t *testing.T) {
+	before := droppedCount(t, "sampled", "info")
+	l, capture, err := logger.NewInMemory(&logger.Config{
+		Level:    "info",
+		Format:   "json",
+		Sampling: logger.SamplingConfig{Initial: 2, Thereafter: 5, Tick: time.Minute},
+	})
+	require.NoError(t, err)
+
+	for range 12 {
+		l.Info("hot loop")
+	}
+	l.Info("other")
+
+	// 2 initial entries, then the 5th and 10th of the remaining 10.
+	require.Len(t, capture.All(), 5)
+	require.Equal(t, float64(8), droppedCount(t, "sampled", "info")-before)
+}
+
+func Test_NewInMemory_RateLimit_CapsPerMessage(t *testing.T) {
+	before := droppedCount(t, "rate_limited", "warn")
+	l, capture, err := logger.NewInMemory(&logger.Config{
+		Level:    "info",
+		Format:   "json",
+		Sampling: logger.SamplingConfig{RateLimit: 3, Tick: time.Minute},
+	})
+	require.NoError(t, err)
+
+	for range 10 {
+		l.Warn("retrying")
+		l.Info("progress")
+	}
+
+	require.Len(t, capture.All(), 6)
+	require.Equal(t, float64(7), droppedCount(t, "rate_limited", "warn")-before)
+}
+
+func Test_NewInMemory_ExemptErrors_WritesAllErrors(t *testing.T) {
+	l, capture, err := logger.NewInMemory(&logger.Config{
+		Level:    "info",
+		Format:   "json",
+		Sampling: logger.SamplingConfig{Initial: 1, RateLimit: 1, Tick: time.Minute, ExemptErrors: true},
+	})
+	require.NoError(t, err)
+
+	for range 5 {
+		l.Error("failed")
+		l.Info("progress")
+	}
+
+	require.Len(t, capture.All(), 6)
+}
+
+func Test_Config_InvalidSampling_ReturnsError(t *testing.T) {
+	cfg := &logger.Config{Level: "info", Format: "json", Sampling: logger.SamplingConfig{RateLimit: 1}}
+
+	require.ErrorContains(t, cfg.Validate(), "sampling -> tick must be > 0")
+}
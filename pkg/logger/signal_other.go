@@ -0,0 +1,11 @@
+//go:build !unix
+
+package logger
+
+import "context"
+
+// HandleLevelSignals is a no-op on platforms without SIGUSR1 and SIGUSR2.
+func HandleLevelSignals(ctx context.Context) {}
+
+// HandleSignals is a no-op on platforms without SIGUSR1 and SIGUSR2.
+func (lv *Levels) HandleSignals(ctx context.Context) {}
@@ -0,0 +1,39 @@
+//go:build unix
+
+package logger
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// HandleLevelSignals calls Levels.HandleSignals for the levels of L.
+func HandleLevelSignals(ctx context.Context) {
+	currentLevels().HandleSignals(ctx)
+}
+
+// HandleSignals toggles debug logging on SIGUSR1 and restores the configured
+// root level on SIGUSR2 until ctx is done.
+func (lv *Levels) HandleSignals(ctx context.Context) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGUSR1, syscall.SIGUSR2)
+
+	go func() {
+		defer signal.Stop(sigCh)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case sig := <-sigCh:
+				if sig == syscall.SIGUSR1 {
+					lv.root.toggleDebug()
+				} else {
+					lv.root.reset()
+				}
+			}
+		}
+	}()
+}
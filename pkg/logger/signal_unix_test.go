@@ -0,0 +1,32 @@
+//go:build unix
+
+package logger_test
+
+import (
+	"context"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/sangrita-tech/platform-go-pkg/pkg/logger"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func Test_HandleLevelSignals_SIGUSR1TogglesDebug(t *testing.T) {
+	require.NoError(t, logger.SetLevel("info", 0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logger.HandleLevelSignals(ctx)
+
+	hasLevel := func(lvl zapcore.Level) func() bool {
+		return func() bool { return logger.Level().Level() == lvl }
+	}
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+	require.Eventually(t, hasLevel(zapcore.DebugLevel), time.Second, 10*time.Millisecond)
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR2))
+	require.Eventually(t, hasLevel(zapcore.InfoLevel), time.Second, 10*time.Millisecond)
+}
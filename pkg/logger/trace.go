@@ -0,0 +1,142 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// TraceConfig controls how FromContext correlates entries with the
+// OpenTelemetry span in the context. With RecordErrors, error and higher
+// entries are also added to the span as events.
+type TraceConfig struct {
+	TraceIDKey   string `yaml:"traceIdKey" env:"ID_KEY" env-default:"trace_id"`
+	SpanIDKey    string `yaml:"spanIdKey" env:"SPAN_ID_KEY" env-default:"span_id"`
+	RecordErrors bool   `yaml:"recordErrors" env:"RECORD_ERRORS" env-default:"false"`
+}
+
+// traceSettings are the trace settings of a logger and the redaction its
+// span events get.
+type traceSettings struct {
+	cfg TraceConfig
+	red *redactor
+}
+
+var defaultTraceSettings = &traceSettings{cfg: TraceConfig{TraceIDKey: "trace_id", SpanIDKey: "span_id"}}
+
+// traceCore carries the trace settings of the logger it is built into, for
+// FromContext to find.
+type traceCore struct {
+	zapcore.Core
+	settings *traceSettings
+}
+
+func withTrace(core zapcore.Core, cfg *Config, red *redactor) zapcore.Core {
+	s := &traceSettings{cfg: cfg.Trace, red: red}
+	if s.cfg.TraceIDKey == "" {
+		s.cfg.TraceIDKey = "trace_id"
+	}
+	if s.cfg.SpanIDKey == "" {
+		s.cfg.SpanIDKey = "span_id"
+	}
+	return &traceCore{Core: core, settings: s}
+}
+
+func (c *traceCore) With(fields []zapcore.Field) zapcore.Core {
+	return &traceCore{Core: c.Core.With(fields), settings: c.settings}
+}
+
+func (c *traceCore) unwrap() zapcore.Core { return c.Core }
+
+// traceSettingsOf returns the trace settings of the logger whose core is
+// core, or the defaults if it was not built by New.
+func traceSettingsOf(core zapcore.Core) *traceSettings {
+	if c, ok := innerCore[*traceCore](core); ok {
+		return c.settings
+	}
+	return defaultTraceSettings
+}
+
+// withSpan adds the ids of the span in ctx to l and, if configured, records
+// its error entries on the span.
+func withSpan(ctx context.Context, l *zap.Logger) *zap.Logger {
+	span := trace.SpanFromContext(ctx)
+	sc := span.SpanContext()
+	if !sc.IsValid() {
+		return l
+	}
+
+	s := traceSettingsOf(l.Core())
+	cfg := &s.cfg
+	if cfg.RecordErrors && span.IsRecording() {
+		l = l.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			return Tee(core, &spanEventCore{span: span, red: s.red})
+		}))
+	}
+
+	return l.With(
+		zap.String(cfg.TraceIDKey, sc.TraceID().String()),
+		zap.String(cfg.SpanIDKey, sc.SpanID().String()),
+	)
+}
+
+// spanEventCore adds error and higher entries as events to a span, redacted
+// like the logger's outputs.
+type spanEventCore struct {
+	span   trace.Span
+	red    *redactor
+	fields []zapcore.Field
+}
+
+func (c *spanEventCore) Enabled(lvl zapcore.Level) bool {
+	return lvl >= zapcore.ErrorLevel
+}
+
+func (c *spanEventCore) With(fields []zapcore.Field) zapcore.Core {
+	return &spanEventCore{span: c.span, red: c.red, fields: append(c.fields[:len(c.fields):len(c.fields)], fields...)}
+}
+
+func (c *spanEventCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *spanEventCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range append(c.fields[:len(c.fields):len(c.fields)], fields...) {
+		if c.red != nil {
+			f = c.red.field(f)
+		}
+		f.AddTo(enc)
+	}
+
+	msg := ent.Message
+	if c.red != nil {
+		msg = c.red.redact(msg)
+	}
+
+	attrs := []attribute.KeyValue{
+		attribute.String("log.severity", ent.Level.CapitalString()),
+		attribute.String("log.message", msg),
+	}
+	for k, v := range enc.Fields {
+		key := k
+		if k == "error" {
+			key = "exception.message"
+		}
+		attrs = append(attrs, attribute.String(key, fmt.Sprint(v)))
+	}
+
+	c.span.AddEvent("log", trace.WithAttributes(attrs...), trace.WithTimestamp(ent.Time))
+	return nil
+}
+
+func (c *spanEventCore) Sync() error {
+	return nil
+}
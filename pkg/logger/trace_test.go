@@ -0,0 +1,136 @@
+package logger_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sangrita-tech/platform-go-pkg/pkg/logger"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/zap"
+)
+
+func startSpan(t *testing.T) (context.Context, *tracetest.SpanRecorder) {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+
+	ctx, span := tp.Tracer("test").Start(context.Background(), "op")
+	t.Cleanup(func() { span.End() })
+	return ctx, recorder
+}
+
+func Test_FromContext_Span_AddsTraceFields(t *testing.T) {
+	l, capture, err := logger.NewInMemory(&logger.Config{
+		Level:  "info",
+		Format: "json",
+		Trace:  logger.TraceConfig{TraceIDKey: "trace.id", SpanIDKey: "span.id"},
+	})
+	require.NoError(t, err)
+	ctx, _ := startSpan(t)
+	ctx = logger.WithContext(ctx, l)
+
+	logger.FromContext(logger.WithRequestID(ctx, "req-1")).Info("traced")
+	logger.FromContext(context.Background()).Info("untraced")
+
+	entries := capture.All()
+	require.Len(t, entries, 1)
+	require.Len(t, entries[0]["trace.id"], 32)
+	require.Len(t, entries[0]["span.id"], 16)
+	require.Equal(t, "req-1", entries[0][logger.FieldRequestID])
+}
+
+func Test_FromContext_RecordErrors_AddsSpanEvents(t *testing.T) {
+	l, _, err := logger.NewInMemory(&logger.Config{
+		Level:  "info",
+		Format: "json",
+		Trace:  logger.TraceConfig{RecordErrors: true},
+	})
+	require.NoError(t, err)
+	ctx, recorder := startSpan(t)
+	ctx = logger.WithContext(ctx, l)
+
+	logger.FromContext(ctx).Info("fine")
+	logger.FromContext(ctx).Error("sync failed", zap.Error(errors.New("conflict")))
+
+	spans := recorder.Started()
+	require.Len(t, spans, 1)
+	events := spans[0].Events()
+	require.Len(t, events, 1)
+
+	attrs := map[string]string{}
+	for _, kv := range events[0].Attributes {
+		attrs[string(kv.Key)] = kv.Value.Emit()
+	}
+	require.Equal(t, "sync failed", attrs["log.message"])
+	require.Equal(t, "ERROR", attrs["log.severity"])
+	require.Equal(t, "conflict", attrs["exception.message"])
+}
+
+func Test_FromContext_LoggersWithDifferentTraceConfigs_KeepTheirKeys(t *testing.T) {
+	dotted, dottedCapture, err := logger.NewInMemory(&logger.Config{
+		Level:  "info",
+		Format: "json",
+		Trace:  logger.TraceConfig{TraceIDKey: "trace.id", SpanIDKey: "span.id"},
+	})
+	require.NoError(t, err)
+	plain, plainCapture, err := logger.NewInMemory(&logger.Config{Level: "info", Format: "json"})
+	require.NoError(t, err)
+
+	ctx, _ := startSpan(t)
+	logger.FromContext(logger.WithContext(ctx, dotted)).Info("dotted")
+	logger.FromContext(logger.WithContext(ctx, plain)).Info("plain")
+
+	require.Contains(t, dottedCapture.All()[0], "trace.id")
+	require.Contains(t, plainCapture.All()[0], "trace_id")
+	require.NotContains(t, plainCapture.All()[0], "trace.id")
+}
+
+func Test_FromContext_RecordErrors_RedactsSpanEvents(t *testing.T) {
+	l, _, err := logger.NewInMemory(&logger.Config{
+		Level:  "info",
+		Format: "json",
+		Trace:  logger.TraceConfig{RecordErrors: true},
+	})
+	require.NoError(t, err)
+	ctx, recorder := startSpan(t)
+	ctx = logger.WithContext(ctx, l)
+
+	logger.FromContext(ctx).With(zap.String("password", "hunter2")).Error("login failed for Bearer abc", zap.String("api_key", "k-1"))
+
+	attrs := map[string]string{}
+	for _, kv := range recorder.Started()[0].Events()[0].Attributes {
+		attrs[string(kv.Key)] = kv.Value.Emit()
+	}
+	require.Equal(t, "login failed for [REDACTED]", attrs["log.message"])
+	require.Equal(t, "[REDACTED]", attrs["password"])
+	require.Equal(t, "[REDACTED]", attrs["api_key"])
+}
+
+func Test_FromContext_RecordErrors_KeepsTraceKeysAndLevels(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	l, cleanup, err := logger.New(&logger.Config{
+		Level:   "info",
+		Format:  "json",
+		Outputs: []logger.OutputConfig{{Type: logger.OutputFile, Path: path}},
+		Trace:   logger.TraceConfig{TraceIDKey: "trace.id", SpanIDKey: "span.id", RecordErrors: true},
+	})
+	require.NoError(t, err)
+	ctx, _ := startSpan(t)
+
+	traced := logger.FromContext(logger.WithContext(ctx, l))
+	logger.FromContext(logger.WithContext(ctx, traced)).Info("twice")
+	require.NotNil(t, logger.LevelsOf(traced))
+	cleanup()
+
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(raw), `"trace.id"`)
+	require.NotContains(t, string(raw), `"trace_id"`)
+}